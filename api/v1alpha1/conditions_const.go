@@ -48,6 +48,12 @@ const (
 
 	// DeviceShutDownReason documents that the device is shut down.
 	DeviceShutDownReason = "DeviceShutDown"
+
+	// DevicePowerOffCalledReason documents that the device has been powered off, because it did not shut down in time.
+	DevicePowerOffCalledReason = "DevicePowerOffCalled"
+
+	// DeviceReprovisioningReason indicates that the device gets provisioned again by remediation.
+	DeviceReprovisioningReason = "DeviceReprovisioning"
 )
 
 const (
@@ -133,3 +139,60 @@ const (
 	// KubeAPIServerNotRespondingReason indicates that the api server cannot be reached.
 	KubeAPIServerNotRespondingReason = "KubeAPIServerNotResponding"
 )
+
+const (
+	// DNSRecordsReadyCondition reports on whether the DNS records managed in the Hivelocity DNS zone are up to date.
+	DNSRecordsReadyCondition clusterv1.ConditionType = "DNSRecordsReady"
+
+	// DNSRecordsReconcileFailedReason indicates that the DNS records could not be reconciled.
+	DNSRecordsReconcileFailedReason = "DNSRecordsReconcileFailed"
+
+	// NoHealthyControlPlaneReason indicates that there is no healthy control plane which the API endpoint record could point to.
+	NoHealthyControlPlaneReason = "NoHealthyControlPlane"
+)
+
+const (
+	// NetworkReachableCondition reports on whether the IPs of the device are reachable. Hivelocity null-routes IPs, for example during DDoS attacks.
+	NetworkReachableCondition clusterv1.ConditionType = "NetworkReachable"
+
+	// IPNullRoutedReason indicates that an IP of the device is null-routed.
+	IPNullRoutedReason = "IPNullRouted"
+
+	// NullRoutesUnavailableReason indicates that the null routes could not be listed, e.g. because the API key
+	// has no permissions for the network API.
+	NullRoutesUnavailableReason = "NullRoutesUnavailable"
+)
+
+const (
+	// DeviceBondedCondition reports on whether the NICs of the device are bonded.
+	DeviceBondedCondition clusterv1.ConditionType = "DeviceBonded"
+
+	// BondPendingReason indicates that the network task which bonds the NICs is still running.
+	BondPendingReason = "BondPending"
+
+	// BondFailedReason indicates that the NICs could not be bonded.
+	BondFailedReason = "BondFailed"
+)
+
+const (
+	// HardwareHealthyCondition reports on whether the IPMI sensors of the device are healthy.
+	HardwareHealthyCondition clusterv1.ConditionType = "HardwareHealthy"
+
+	// HardwareSensorsFailingReason indicates that IPMI sensors of the device report a failure, e.g. of a fan or PSU.
+	HardwareSensorsFailingReason = "HardwareSensorsFailing"
+
+	// IPMISensorsUnavailableReason indicates that the IPMI sensors of the device could not be read.
+	IPMISensorsUnavailableReason = "IPMISensorsUnavailable"
+)
+
+const (
+	// NodeJoinedCondition reports on whether the workload cluster has a Node with the providerID of the machine.
+	NodeJoinedCondition clusterv1.ConditionType = "NodeJoined"
+
+	// WaitingForNodeReason indicates that the node of the provisioned device has not joined the cluster yet.
+	WaitingForNodeReason = "WaitingForNode"
+
+	// NodeJoinTimeoutReason indicates that the node did not join the cluster within the NodeJoinTimeout,
+	// e.g. because cloud-init failed.
+	NodeJoinTimeoutReason = "NodeJoinTimeout"
+)
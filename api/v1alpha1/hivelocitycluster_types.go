@@ -17,6 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -27,6 +30,11 @@ const (
 	// resources associated with HivelocityCluster before removing it from the
 	// apiserver.
 	ClusterFinalizer = "hivelocitycluster.infrastructure.cluster.x-k8s.io"
+
+	// ClearPermanentErrorAnnotation on a HivelocityCluster contains a comma separated list of device IDs.
+	// The controller removes the permanent error tag of these devices if they pass a health check, and then
+	// removes the annotation.
+	ClearPermanentErrorAnnotation = "hivelocitycluster.infrastructure.cluster.x-k8s.io/clear-permanent-error"
 )
 
 // HivelocityClusterSpec defines the desired state of HivelocityCluster.
@@ -39,11 +47,90 @@ type HivelocityClusterSpec struct {
 	ControlPlaneRegion Region `json:"controlPlaneRegion"`
 
 	// HivelocitySecret is a reference to a Kubernetes Secret.
+	// If the secret does not exist and UseDefaultCredential is set, the default credential of the manager is used.
 	HivelocitySecret HivelocitySecretRef `json:"hivelocitySecretRef"`
 
+	// TargetSecret configures the copy of the API key into the kube-system namespace of the workload cluster,
+	// which is used by the cloud controller manager. By default, the API key of the HivelocitySecret gets copied.
+	// +optional
+	TargetSecret *TargetSecretSpec `json:"targetSecret,omitempty"`
+
 	// SSHKey is cluster wide. Valid value is a valid SSH key name.
 	// +optional
 	SSHKey *SSHKey `json:"sshKey,omitempty"`
+
+	// DNS configures DNS records which get managed in a Hivelocity DNS zone.
+	// If not set, no DNS records get managed.
+	// +optional
+	DNS *DNSSpec `json:"dns,omitempty"`
+
+	// IPMIWhitelist is a list of IPs which get whitelisted for IPMI access to the devices of the cluster.
+	// The whitelist gets applied when a device gets provisioned and cleared when the device gets released.
+	// If empty, the IPMI access of the devices is not changed.
+	// +optional
+	IPMIWhitelist []string `json:"ipmiWhitelist,omitempty"`
+
+	// NetworkPolicy is a minimal firewall for the public ports of the devices. It gets applied as nftables
+	// rules with the cloud-init of the devices. If not set, no rules get added.
+	// +optional
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+}
+
+// NetworkPolicy defines which incoming traffic is allowed on the public ports of the devices.
+// Traffic from private networks, ICMP and replies to outgoing connections are always allowed.
+// All other incoming traffic gets dropped.
+type NetworkPolicy struct {
+	// AllowedTCPPorts are the TCP ports which are reachable from everywhere.
+	// +optional
+	// +kubebuilder:default={22,6443}
+	AllowedTCPPorts []int32 `json:"allowedTCPPorts,omitempty"`
+
+	// AllowedUDPPorts are the UDP ports which are reachable from everywhere.
+	// +optional
+	AllowedUDPPorts []int32 `json:"allowedUDPPorts,omitempty"`
+
+	// AllowedSourceCIDRs are IPv4 and IPv6 networks which may reach all ports. They have to include
+	// the public IPs of all nodes, since the nodes communicate via their public IPs.
+	// +optional
+	AllowedSourceCIDRs []string `json:"allowedSourceCIDRs,omitempty"`
+}
+
+// DNSSpec defines the DNS records which the controller manages in a Hivelocity DNS zone.
+type DNSSpec struct {
+	// Zone is the name of the Hivelocity DNS zone, e.g. "example.com". The zone must exist already.
+	// +kubebuilder:validation:MinLength=1
+	Zone string `json:"zone"`
+
+	// APIEndpointRecord is the name of the A record (relative to the zone) which points to the
+	// healthy control-plane devices.
+	// +optional
+	// +kubebuilder:default=api
+	APIEndpointRecord string `json:"apiEndpointRecord,omitempty"`
+
+	// NodeRecords enables an A and a PTR record for each node. The records match the hostname
+	// of the provisioned device, which is "<machine-name>.<zone>".
+	// +optional
+	NodeRecords bool `json:"nodeRecords,omitempty"`
+
+	// TTL of the records in seconds.
+	// +optional
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=60
+	TTL int32 `json:"ttl,omitempty"`
+}
+
+// APIEndpointFQDN returns the fully qualified name of the API endpoint record.
+func (dns *DNSSpec) APIEndpointFQDN() string {
+	name := dns.APIEndpointRecord
+	if name == "" {
+		name = "api"
+	}
+	return dns.FQDN(name)
+}
+
+// FQDN returns the fully qualified name of a record in the zone.
+func (dns *DNSSpec) FQDN(name string) string {
+	return fmt.Sprintf("%s.%s", name, strings.TrimSuffix(dns.Zone, "."))
 }
 
 // HivelocitySecretRef defines the name of the Secret and the relevant key in the secret to access the Hivelocity API.
@@ -55,6 +142,32 @@ type HivelocitySecretRef struct {
 	// +optional
 	// +kubebuilder:default=HIVELOCITY_API_KEY
 	Key string `json:"key,omitempty"`
+
+	// SecondaryKey is the key of a second API key in the secret. It is used if the Hivelocity API rejects
+	// the API key of Key. This allows rotating API keys without downtime: add the new key as secondary key,
+	// revoke the old one and then move the new key to Key.
+	// +optional
+	SecondaryKey string `json:"secondaryKey,omitempty"`
+
+	// UseDefaultCredential allows using the default credential of the manager if the secret does not exist.
+	// Without it, a missing secret is an error, so that a deleted or misspelled secret does not give the cluster
+	// access to the Hivelocity account of the manager.
+	// +optional
+	UseDefaultCredential bool `json:"useDefaultCredential,omitempty"`
+}
+
+// TargetSecretSpec configures the copy of the API key into the workload cluster.
+type TargetSecretSpec struct {
+	// Disabled disables the copy. Use it if the workload cluster gets its credentials in another way,
+	// e.g. from an external secret store.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Key is the key in the HivelocitySecret of the API key which gets copied. Use it to give the workload
+	// cluster an API key with fewer permissions than the one of the management cluster.
+	// The API key is stored under the key of the HivelocitySecret. Defaults to the key of the HivelocitySecret.
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // SSHKey defines the SSHKey for Hivelocity.
@@ -72,6 +185,34 @@ type HivelocityClusterStatus struct {
 	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
 
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// QuarantinedDevices are the devices which may be used by CAPHV, but have the permanent error tag.
+	// They need a manual intervention, see ClearPermanentErrorAnnotation.
+	// +optional
+	QuarantinedDevices []QuarantinedDevice `json:"quarantinedDevices,omitempty"`
+
+	// ActiveSecretKey is the key in the Hivelocity secret of the API key which is in use.
+	// It differs from the key of the HivelocitySecret only while the secondary key is in use.
+	// It is empty if the default credential of the manager is in use.
+	// +optional
+	ActiveSecretKey string `json:"activeSecretKey,omitempty"`
+}
+
+// QuarantinedDevice is a device with the permanent error tag.
+type QuarantinedDevice struct {
+	// DeviceID is the ID of the device.
+	DeviceID int32 `json:"deviceID"`
+
+	// Reason is the reason in the permanent error tag, e.g. "reloading-since" or "remediation-failed".
+	Reason string `json:"reason"`
+
+	// Since is the time the error occurred, if the tag contains it.
+	// +optional
+	Since *metav1.Time `json:"since,omitempty"`
+
+	// TicketID is the ID of the support ticket which was opened for the device.
+	// +optional
+	TicketID int32 `json:"ticketID,omitempty"`
 }
 
 // +kubebuilder:object:root=true
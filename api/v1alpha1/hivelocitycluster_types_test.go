@@ -41,3 +41,17 @@ func TestClusterDeviceTagOwned(t *testing.T) {
 		t.Fatalf("wrong device tag. Expect %+v, got %+v", expectDeviceTag, deviceTag)
 	}
 }
+
+func TestDNSSpecFQDN(t *testing.T) {
+	dns := DNSSpec{Zone: "example.com."}
+	if got := dns.APIEndpointFQDN(); got != "api.example.com" {
+		t.Fatalf("wrong API endpoint FQDN. Expect %q, got %q", "api.example.com", got)
+	}
+	dns.APIEndpointRecord = "k8s"
+	if got := dns.APIEndpointFQDN(); got != "k8s.example.com" {
+		t.Fatalf("wrong API endpoint FQDN. Expect %q, got %q", "k8s.example.com", got)
+	}
+	if got := dns.FQDN("node-1"); got != "node-1.example.com" {
+		t.Fatalf("wrong FQDN. Expect %q, got %q", "node-1.example.com", got)
+	}
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"net"
+
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -47,13 +51,13 @@ var _ webhook.Validator = &HivelocityCluster{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
 func (r *HivelocityCluster) ValidateCreate() (admission.Warnings, error) {
 	hivelocityclusterlog.V(1).Info("validate create", "name", r.Name)
-	return nil, nil
+	return nil, aggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, r.validateSpec())
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
 func (r *HivelocityCluster) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
 	hivelocityclusterlog.V(1).Info("validate update", "name", r.Name)
-	return nil, nil
+	return nil, aggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, r.validateSpec())
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -61,3 +65,55 @@ func (r *HivelocityCluster) ValidateDelete() (admission.Warnings, error) {
 	hivelocityclusterlog.V(1).Info("validate delete", "name", r.Name)
 	return nil, nil
 }
+
+// validateSpec validates the fields of the spec which can't be validated with the OpenAPI schema.
+func (r *HivelocityCluster) validateSpec() field.ErrorList {
+	allErrs := r.validateControlPlaneEndpoint()
+
+	for i, ip := range r.Spec.IPMIWhitelist {
+		if net.ParseIP(ip) == nil {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec", "ipmiWhitelist").Index(i), ip, "must be an IP address"),
+			)
+		}
+	}
+
+	if policy := r.Spec.NetworkPolicy; policy != nil {
+		for i, cidr := range policy.AllowedSourceCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				allErrs = append(allErrs,
+					field.Invalid(field.NewPath("spec", "networkPolicy", "allowedSourceCIDRs").Index(i), cidr, err.Error()),
+				)
+			}
+		}
+		allErrs = append(allErrs, validatePorts(field.NewPath("spec", "networkPolicy", "allowedTCPPorts"), policy.AllowedTCPPorts)...)
+		allErrs = append(allErrs, validatePorts(field.NewPath("spec", "networkPolicy", "allowedUDPPorts"), policy.AllowedUDPPorts)...)
+	}
+	return allErrs
+}
+
+func validatePorts(path *field.Path, ports []int32) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, port := range ports {
+		for _, msg := range validation.IsValidPortNum(int(port)) {
+			allErrs = append(allErrs, field.Invalid(path.Index(i), port, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateControlPlaneEndpoint checks that the host of the control plane endpoint is an IPv4 address,
+// an IPv6 address or a DNS name. IPv6 addresses must not be enclosed in brackets.
+func (r *HivelocityCluster) validateControlPlaneEndpoint() field.ErrorList {
+	endpoint := r.Spec.ControlPlaneEndpoint
+	if endpoint == nil || endpoint.Host == "" {
+		return nil
+	}
+	if net.ParseIP(endpoint.Host) != nil || len(validation.IsDNS1123Subdomain(endpoint.Host)) == 0 {
+		return nil
+	}
+	return field.ErrorList{
+		field.Invalid(field.NewPath("spec", "controlPlaneEndpoint", "host"), endpoint.Host,
+			"must be an IPv4 address, an IPv6 address without brackets or a DNS name"),
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestHivelocityClusterWebhook_ValidateCreate_valid(t *testing.T) {
+	hc := HivelocityCluster{}
+	for _, host := range []string{"", "192.0.2.10", "2001:db8::2", "api.example.com"} {
+		hc.Spec.ControlPlaneEndpoint = &clusterv1.APIEndpoint{Host: host, Port: 6443}
+		warnings, err := hc.ValidateCreate()
+		require.Nil(t, err, host)
+		require.Len(t, warnings, 0)
+	}
+}
+
+func TestHivelocityClusterWebhook_ValidateCreate_invalid(t *testing.T) {
+	hc := HivelocityCluster{}
+	for _, host := range []string{"[2001:db8::2]", "192.0.2.10:6443", "api_example.com"} {
+		hc.Spec.ControlPlaneEndpoint = &clusterv1.APIEndpoint{Host: host, Port: 6443}
+		warnings, err := hc.ValidateCreate()
+		require.NotNil(t, err, host)
+		require.Len(t, warnings, 0)
+	}
+}
+
+func TestHivelocityClusterWebhook_ValidateCreate_networkSettings(t *testing.T) {
+	hc := HivelocityCluster{}
+	hc.Spec.IPMIWhitelist = []string{"192.0.2.10", "2001:db8::2"}
+	hc.Spec.NetworkPolicy = &NetworkPolicy{
+		AllowedTCPPorts:    []int32{22, 6443},
+		AllowedSourceCIDRs: []string{"192.0.2.0/24", "2001:db8::/64"},
+	}
+	_, err := hc.ValidateCreate()
+	require.Nil(t, err)
+
+	hc.Spec.IPMIWhitelist = []string{"192.0.2.0/24"}
+	_, err = hc.ValidateCreate()
+	require.NotNil(t, err)
+
+	hc.Spec.IPMIWhitelist = nil
+	hc.Spec.NetworkPolicy.AllowedSourceCIDRs = []string{"192.0.2.10"}
+	_, err = hc.ValidateCreate()
+	require.NotNil(t, err)
+
+	hc.Spec.NetworkPolicy.AllowedSourceCIDRs = nil
+	hc.Spec.NetworkPolicy.AllowedUDPPorts = []int32{0}
+	_, err = hc.ValidateCreate()
+	require.NotNil(t, err)
+}
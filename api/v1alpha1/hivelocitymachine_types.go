@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
 	hv "github.com/hivelocity/hivelocity-client-go/client"
@@ -36,6 +37,24 @@ const (
 	// resources associated with HivelocityMachine before removing it from the
 	// apiserver.
 	MachineFinalizer = "hivelocitymachine.infrastructure.cluster.x-k8s.io"
+
+	// DefaultPowerOffTimeout is the default time to wait for a graceful shutdown before the device gets powered off.
+	DefaultPowerOffTimeout = 5 * time.Minute
+
+	// DefaultPowerOnDelay is the default time a device has to be powered off before it gets turned on with
+	// PowerOnPolicy AfterDelay.
+	DefaultPowerOnDelay = 5 * time.Minute
+
+	// DefaultNodeJoinTimeout is the default time the node of a provisioned device has to join the cluster.
+	DefaultNodeJoinTimeout = 15 * time.Minute
+
+	// DefaultReloadTimeout is the default time a powered off device may be reloading before it gets the permanent
+	// error tag.
+	DefaultReloadTimeout = 5 * time.Minute
+
+	// DefaultProvisioningReloadTimeout is the default time a powered on device may be reloading before it gets the
+	// permanent error tag. The device is reloading and powered on during provisioning, which takes longer.
+	DefaultProvisioningReloadTimeout = 25 * time.Minute
 )
 
 const (
@@ -45,6 +64,9 @@ const (
 	// FailureMessageDeviceTagsInvalid indicates that the associated device has invalid tags.
 	// This is probably due to a user changing device tags on his own.
 	FailureMessageDeviceTagsInvalid = "device tags invalid"
+
+	// FailureMessageDeviceNullRouted indicates that an IP of the associated device is null-routed.
+	FailureMessageDeviceNullRouted = "device IP is null-routed"
 )
 
 var (
@@ -102,11 +124,74 @@ type HivelocityMachineSpec struct {
 	// +kubebuilder:validation:MinLength=1
 	ImageName string `json:"imageName"`
 
+	// RemediateNullRoutedDevice sets the machine to failed if an IP of the device is null-routed, e.g. because of a DDoS attack.
+	// A MachineHealthCheck replaces failed machines. If false, only the NetworkReachable condition reports null routes.
+	// +optional
+	RemediateNullRoutedDevice bool `json:"remediateNullRoutedDevice,omitempty"`
+
+	// Network configures the network of the device.
+	// +optional
+	Network *MachineNetwork `json:"network,omitempty"`
+
+	// PowerOffTimeout is the time to wait for a graceful shutdown of the device before it gets powered off.
+	// Defaults to 5m.
+	// +optional
+	PowerOffTimeout *metav1.Duration `json:"powerOffTimeout,omitempty"`
+
+	// PowerOnPolicy defines whether the controller turns a provisioned device on again after it was powered off,
+	// e.g. by accident or after a power event. Never (default) only reports the power state.
+	// +kubebuilder:validation:Enum=Never;Always;AfterDelay
+	// +kubebuilder:default=Never
+	// +optional
+	PowerOnPolicy PowerOnPolicy `json:"powerOnPolicy,omitempty"`
+
+	// PowerOnDelay is the time a device has to be powered off before it gets turned on with PowerOnPolicy AfterDelay.
+	// Defaults to 5m.
+	// +optional
+	PowerOnDelay *metav1.Duration `json:"powerOnDelay,omitempty"`
+
+	// NodeJoinTimeout is the time the node of a provisioned device has to join the workload cluster. Otherwise the
+	// NodeJoined condition gets the reason NodeJoinTimeout. Defaults to 15m.
+	// +optional
+	NodeJoinTimeout *metav1.Duration `json:"nodeJoinTimeout,omitempty"`
+
+	// ReloadTimeout is the time a powered off device may be reloading, e.g. during deprovisioning, before it gets
+	// the permanent error tag and another device is used. Defaults to 5m.
+	// +optional
+	ReloadTimeout *metav1.Duration `json:"reloadTimeout,omitempty"`
+
+	// ProvisioningReloadTimeout is the time a powered on device may be reloading, e.g. during provisioning, before
+	// it gets the permanent error tag and another device is used. Defaults to 25m.
+	// +optional
+	ProvisioningReloadTimeout *metav1.Duration `json:"provisioningReloadTimeout,omitempty"`
+
 	// Status contains all status information of the controller. Do not edit these values!
 	// +optional
 	Status ControllerGeneratedStatus `json:"status,omitempty"`
 }
 
+// PowerOnPolicy defines what happens if a provisioned device is powered off.
+type PowerOnPolicy string
+
+const (
+	// PowerOnPolicyNever leaves powered off devices alone.
+	PowerOnPolicyNever PowerOnPolicy = "Never"
+
+	// PowerOnPolicyAlways turns powered off devices on immediately.
+	PowerOnPolicyAlways PowerOnPolicy = "Always"
+
+	// PowerOnPolicyAfterDelay turns devices on which have been powered off for PowerOnDelay.
+	PowerOnPolicyAfterDelay PowerOnPolicy = "AfterDelay"
+)
+
+// MachineNetwork configures the network of the device.
+type MachineNetwork struct {
+	// Bond bonds the NICs of the device for redundancy. This is only possible for devices with multiple NICs.
+	// The bond gets applied before the device gets provisioned and removed when the device gets released.
+	// +optional
+	Bond bool `json:"bond,omitempty"`
+}
+
 // DeviceSelector specifies matching criteria for tags on devices.
 // This is used to target a specific set of devices that can be claimed by the HivelocityMachine.
 type DeviceSelector struct {
@@ -168,6 +253,19 @@ type ControllerGeneratedStatus struct {
 	// Time stamp of last update of status.
 	// +optional
 	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+
+	// StateChangedAt is the time when the machine entered the current provisioning state.
+	// +optional
+	StateChangedAt *metav1.Time `json:"stateChangedAt,omitempty"`
+
+	// ProvisioningStartedAt is the time when the machine started to associate a device.
+	// It is removed when the device is provisioned.
+	// +optional
+	ProvisioningStartedAt *metav1.Time `json:"provisioningStartedAt,omitempty"`
+
+	// BondTaskID is the ID of the pending network task which bonds the NICs of the device.
+	// +optional
+	BondTaskID string `json:"bondTaskID,omitempty"`
 }
 
 // HivelocityDeviceType defines the Hivelocity device type.
@@ -175,6 +273,18 @@ type ControllerGeneratedStatus struct {
 // hvlabel:foo=bar
 type HivelocityDeviceType string // TODO: this should not be an enum. Rename to HVLabel, and make a label selector.
 
+// MaxDeviceEvents is the maximum number of device events in the status of the HivelocityMachine.
+const MaxDeviceEvents = 10
+
+// DeviceEvent is an event of the device in the Hivelocity API, e.g. a reload or a power action.
+type DeviceEvent struct {
+	// Action describes what happened to the device.
+	Action string `json:"action"`
+
+	// Time is the time of the event.
+	Time metav1.Time `json:"time"`
+}
+
 // HivelocityMachineStatus defines the observed state of HivelocityMachine.
 type HivelocityMachineStatus struct {
 	// Ready is true when the provider resource is ready.
@@ -191,6 +301,40 @@ type HivelocityMachineStatus struct {
 	// +optional
 	PowerState string `json:"powerState,omitempty"`
 
+	// PowerOnAttempts is the number of times the controller turned the device on because of the PowerOnPolicy.
+	// +optional
+	PowerOnAttempts int32 `json:"powerOnAttempts,omitempty"`
+
+	// LastPowerOnAttempt is the time of the last attempt to turn the device on because of the PowerOnPolicy.
+	// +optional
+	LastPowerOnAttempt *metav1.Time `json:"lastPowerOnAttempt,omitempty"`
+
+	// LastHardwareCheck is the time the IPMI sensors of the device were checked the last time.
+	// +optional
+	LastHardwareCheck *metav1.Time `json:"lastHardwareCheck,omitempty"`
+
+	// LastAddressDiscovery is the time the IP addresses of the device were discovered the last time.
+	// +optional
+	LastAddressDiscovery *metav1.Time `json:"lastAddressDiscovery,omitempty"`
+
+	// IPMIWhitelist are the IPs which were whitelisted for IPMI access to the device. They are only added once.
+	// The Hivelocity API cannot remove them, so they stay whitelisted when the device gets released.
+	// +optional
+	IPMIWhitelist []string `json:"ipmiWhitelist,omitempty"`
+
+	// LastNullRouteCheck is the time the null routes of the IPs of the device were checked the last time.
+	// +optional
+	LastNullRouteCheck *metav1.Time `json:"lastNullRouteCheck,omitempty"`
+
+	// LastDeviceEventsPoll is the time the events of the device were read the last time.
+	// +optional
+	LastDeviceEventsPoll *metav1.Time `json:"lastDeviceEventsPoll,omitempty"`
+
+	// DeviceEvents are the latest events of the device in the Hivelocity API, oldest first.
+	// At most MaxDeviceEvents events are kept.
+	// +optional
+	DeviceEvents []DeviceEvent `json:"deviceEvents,omitempty"`
+
 	// FailureReason will be set in the event that there is a terminal problem
 	// reconciling the Machine and will contain a succinct value suitable
 	// for machine interpretation.
@@ -248,6 +392,48 @@ func (r *HivelocityMachine) SetConditions(conditions clusterv1.Conditions) {
 	r.Status.Conditions = conditions
 }
 
+// PowerOffTimeout returns the time to wait for a graceful shutdown before the device gets powered off.
+func (r *HivelocityMachine) PowerOffTimeout() time.Duration {
+	if r.Spec.PowerOffTimeout == nil || r.Spec.PowerOffTimeout.Duration <= 0 {
+		return DefaultPowerOffTimeout
+	}
+	return r.Spec.PowerOffTimeout.Duration
+}
+
+// NodeJoinTimeout returns the time the node of a provisioned device has to join the workload cluster.
+func (r *HivelocityMachine) NodeJoinTimeout() time.Duration {
+	if r.Spec.NodeJoinTimeout == nil || r.Spec.NodeJoinTimeout.Duration <= 0 {
+		return DefaultNodeJoinTimeout
+	}
+	return r.Spec.NodeJoinTimeout.Duration
+}
+
+// ReloadTimeout returns the time the device may be reloading before it gets the permanent error tag.
+// Reloading takes longer if the device is powered on, e.g. during provisioning.
+func (r *HivelocityMachine) ReloadTimeout(poweredOn bool) time.Duration {
+	timeout, defaultTimeout := r.Spec.ReloadTimeout, DefaultReloadTimeout
+	if poweredOn {
+		timeout, defaultTimeout = r.Spec.ProvisioningReloadTimeout, DefaultProvisioningReloadTimeout
+	}
+	if timeout == nil || timeout.Duration <= 0 {
+		return defaultTimeout
+	}
+	return timeout.Duration
+}
+
+// PowerOnDelay returns the time a device has to be powered off before it gets turned on with PowerOnPolicy AfterDelay.
+func (r *HivelocityMachine) PowerOnDelay() time.Duration {
+	if r.Spec.PowerOnDelay == nil || r.Spec.PowerOnDelay.Duration <= 0 {
+		return DefaultPowerOnDelay
+	}
+	return r.Spec.PowerOnDelay.Duration
+}
+
+// BondEnabled returns true if the NICs of the device should be bonded.
+func (r *HivelocityMachine) BondEnabled() bool {
+	return r.Spec.Network != nil && r.Spec.Network.Bond
+}
+
 // SetFailure sets a failure reason and message.
 func (r *HivelocityMachine) SetFailure(reason capierrors.MachineStatusError, message string) {
 	r.Status.FailureReason = &reason
@@ -260,22 +446,27 @@ func (r *HivelocityMachine) SetProviderID(deviceID int32) {
 	r.Spec.ProviderID = &providerID
 }
 
-// SetMachineStatus sets the providerID based on a deviceID.
-func (r *HivelocityMachine) SetMachineStatus(device hv.BareMetalDevice) {
-	r.Status.Addresses = []clusterv1.MachineAddress{
+// SetMachineStatus sets the status of the machine based on the device and its IP addresses.
+// If no IP addresses are given, the primary IP of the device is used.
+func (r *HivelocityMachine) SetMachineStatus(device hv.BareMetalDevice, ipAddresses []clusterv1.MachineAddress) {
+	if len(ipAddresses) == 0 {
+		ipAddresses = []clusterv1.MachineAddress{
+			{
+				Type:    clusterv1.MachineInternalIP,
+				Address: device.PrimaryIp,
+			},
+			{
+				Type:    clusterv1.MachineExternalIP,
+				Address: device.PrimaryIp,
+			},
+		}
+	}
+	r.Status.Addresses = append([]clusterv1.MachineAddress{
 		{
 			Type:    clusterv1.MachineHostName,
 			Address: device.Hostname,
 		},
-		{
-			Type:    clusterv1.MachineInternalIP,
-			Address: device.PrimaryIp,
-		},
-		{
-			Type:    clusterv1.MachineExternalIP,
-			Address: device.PrimaryIp,
-		},
-	}
+	}, ipAddresses...)
 	r.Status.PowerState = device.PowerStatus
 	r.Status.Region = Region(device.LocationName)
 }
@@ -137,6 +137,7 @@ var _ = Describe("Test SetMachineStatus", func() {
 	type testCaseSetMachineStatus struct {
 		existingStatus HivelocityMachineStatus
 		device         hv.BareMetalDevice
+		ipAddresses    []clusterv1.MachineAddress
 		expectStatus   HivelocityMachineStatus
 	}
 
@@ -144,7 +145,7 @@ var _ = Describe("Test SetMachineStatus", func() {
 		func(tc testCaseSetMachineStatus) {
 			hvMachine := HivelocityMachine{}
 			hvMachine.Status = tc.existingStatus
-			hvMachine.SetMachineStatus(tc.device)
+			hvMachine.SetMachineStatus(tc.device, tc.ipAddresses)
 
 			Expect(hvMachine.Status).Should(Equal(tc.expectStatus))
 		},
@@ -211,6 +212,32 @@ var _ = Describe("Test SetMachineStatus", func() {
 				PowerState: "OFF",
 			},
 		}),
+		Entry("dual-stack addresses", testCaseSetMachineStatus{
+			existingStatus: HivelocityMachineStatus{},
+			device: hv.BareMetalDevice{
+				Hostname:     "device-hostname",
+				PrimaryIp:    "127.0.0.1",
+				LocationName: "LAX2",
+				PowerStatus:  "ON",
+			},
+			ipAddresses: []clusterv1.MachineAddress{
+				{Type: clusterv1.MachineInternalIP, Address: "127.0.0.1"},
+				{Type: clusterv1.MachineExternalIP, Address: "127.0.0.1"},
+				{Type: clusterv1.MachineInternalIP, Address: "2001:db8::2"},
+				{Type: clusterv1.MachineExternalIP, Address: "2001:db8::2"},
+			},
+			expectStatus: HivelocityMachineStatus{
+				Addresses: []clusterv1.MachineAddress{
+					{Type: clusterv1.MachineHostName, Address: "device-hostname"},
+					{Type: clusterv1.MachineInternalIP, Address: "127.0.0.1"},
+					{Type: clusterv1.MachineExternalIP, Address: "127.0.0.1"},
+					{Type: clusterv1.MachineInternalIP, Address: "2001:db8::2"},
+					{Type: clusterv1.MachineExternalIP, Address: "2001:db8::2"},
+				},
+				Region:     Region("LAX2"),
+				PowerState: "ON",
+			},
+		}),
 	)
 })
 
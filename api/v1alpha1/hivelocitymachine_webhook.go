@@ -79,6 +79,13 @@ func (r *HivelocityMachine) ValidateUpdate(oldRaw runtime.Object) (admission.War
 		)
 	}
 
+	// Network is immutable
+	if !reflect.DeepEqual(old.Spec.Network, r.Spec.Network) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "network"), r.Spec.Network, "field is immutable"),
+		)
+	}
+
 	return nil, aggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, allErrs)
 }
 
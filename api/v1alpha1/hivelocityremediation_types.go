@@ -30,6 +30,10 @@ const (
 
 	// RemediationTypeReboot sets RemediationType to Reboot.
 	RemediationTypeReboot RemediationType = "Reboot"
+
+	// RemediationTypeReprovision sets RemediationType to Reprovision. The device of the machine gets provisioned again
+	// with fresh bootstrap data. The machine keeps its device and IPs.
+	RemediationTypeReprovision RemediationType = "Reprovision"
 )
 
 const (
@@ -52,6 +56,7 @@ type HivelocityRemediationSpec struct {
 // RemediationStrategy describes how to remediate machines.
 type RemediationStrategy struct {
 	// Type of remediation.
+	// +kubebuilder:validation:Enum=Reboot;Reprovision
 	// +kubebuilder:default=Reboot
 	// +optional
 	Type RemediationType `json:"type,omitempty"`
@@ -21,8 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/errors"
@@ -35,6 +35,14 @@ func (in *ControllerGeneratedStatus) DeepCopyInto(out *ControllerGeneratedStatus
 		in, out := &in.LastUpdated, &out.LastUpdated
 		*out = (*in).DeepCopy()
 	}
+	if in.StateChangedAt != nil {
+		in, out := &in.StateChangedAt, &out.StateChangedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ProvisioningStartedAt != nil {
+		in, out := &in.ProvisioningStartedAt, &out.ProvisioningStartedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerGeneratedStatus.
@@ -47,6 +55,37 @@ func (in *ControllerGeneratedStatus) DeepCopy() *ControllerGeneratedStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSSpec) DeepCopyInto(out *DNSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSSpec.
+func (in *DNSSpec) DeepCopy() *DNSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceEvent) DeepCopyInto(out *DeviceEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceEvent.
+func (in *DeviceEvent) DeepCopy() *DeviceEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceSelector) DeepCopyInto(out *DeviceSelector) {
 	*out = *in
@@ -164,11 +203,31 @@ func (in *HivelocityClusterSpec) DeepCopyInto(out *HivelocityClusterSpec) {
 		**out = **in
 	}
 	out.HivelocitySecret = in.HivelocitySecret
+	if in.TargetSecret != nil {
+		in, out := &in.TargetSecret, &out.TargetSecret
+		*out = new(TargetSecretSpec)
+		**out = **in
+	}
 	if in.SSHKey != nil {
 		in, out := &in.SSHKey, &out.SSHKey
 		*out = new(SSHKey)
 		**out = **in
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSSpec)
+		**out = **in
+	}
+	if in.IPMIWhitelist != nil {
+		in, out := &in.IPMIWhitelist, &out.IPMIWhitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(NetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HivelocityClusterSpec.
@@ -198,6 +257,13 @@ func (in *HivelocityClusterStatus) DeepCopyInto(out *HivelocityClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.QuarantinedDevices != nil {
+		in, out := &in.QuarantinedDevices, &out.QuarantinedDevices
+		*out = make([]QuarantinedDevice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HivelocityClusterStatus.
@@ -369,6 +435,36 @@ func (in *HivelocityMachineSpec) DeepCopyInto(out *HivelocityMachineSpec) {
 		**out = **in
 	}
 	in.DeviceSelector.DeepCopyInto(&out.DeviceSelector)
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(MachineNetwork)
+		**out = **in
+	}
+	if in.PowerOffTimeout != nil {
+		in, out := &in.PowerOffTimeout, &out.PowerOffTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PowerOnDelay != nil {
+		in, out := &in.PowerOnDelay, &out.PowerOnDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NodeJoinTimeout != nil {
+		in, out := &in.NodeJoinTimeout, &out.NodeJoinTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ReloadTimeout != nil {
+		in, out := &in.ReloadTimeout, &out.ReloadTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ProvisioningReloadTimeout != nil {
+		in, out := &in.ProvisioningReloadTimeout, &out.ProvisioningReloadTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -390,6 +486,38 @@ func (in *HivelocityMachineStatus) DeepCopyInto(out *HivelocityMachineStatus) {
 		*out = make([]v1beta1.MachineAddress, len(*in))
 		copy(*out, *in)
 	}
+	if in.LastPowerOnAttempt != nil {
+		in, out := &in.LastPowerOnAttempt, &out.LastPowerOnAttempt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastHardwareCheck != nil {
+		in, out := &in.LastHardwareCheck, &out.LastHardwareCheck
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAddressDiscovery != nil {
+		in, out := &in.LastAddressDiscovery, &out.LastAddressDiscovery
+		*out = (*in).DeepCopy()
+	}
+	if in.IPMIWhitelist != nil {
+		in, out := &in.IPMIWhitelist, &out.IPMIWhitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastNullRouteCheck != nil {
+		in, out := &in.LastNullRouteCheck, &out.LastNullRouteCheck
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDeviceEventsPoll != nil {
+		in, out := &in.LastDeviceEventsPoll, &out.LastDeviceEventsPoll
+		*out = (*in).DeepCopy()
+	}
+	if in.DeviceEvents != nil {
+		in, out := &in.DeviceEvents, &out.DeviceEvents
+		*out = make([]DeviceEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(errors.MachineStatusError)
@@ -516,7 +644,7 @@ func (in *HivelocityMachineTemplateStatus) DeepCopyInto(out *HivelocityMachineTe
 	*out = *in
 	if in.Capacity != nil {
 		in, out := &in.Capacity, &out.Capacity
-		*out = make(v1.ResourceList, len(*in))
+		*out = make(corev1.ResourceList, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val.DeepCopy()
 		}
@@ -760,12 +888,76 @@ func (in *HivelocitySecretRef) DeepCopy() *HivelocitySecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineNetwork) DeepCopyInto(out *MachineNetwork) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineNetwork.
+func (in *MachineNetwork) DeepCopy() *MachineNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicy) DeepCopyInto(out *NetworkPolicy) {
+	*out = *in
+	if in.AllowedTCPPorts != nil {
+		in, out := &in.AllowedTCPPorts, &out.AllowedTCPPorts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedUDPPorts != nil {
+		in, out := &in.AllowedUDPPorts, &out.AllowedUDPPorts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSourceCIDRs != nil {
+		in, out := &in.AllowedSourceCIDRs, &out.AllowedSourceCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicy.
+func (in *NetworkPolicy) DeepCopy() *NetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuarantinedDevice) DeepCopyInto(out *QuarantinedDevice) {
+	*out = *in
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuarantinedDevice.
+func (in *QuarantinedDevice) DeepCopy() *QuarantinedDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(QuarantinedDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemediationStrategy) DeepCopyInto(out *RemediationStrategy) {
 	*out = *in
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
@@ -794,3 +986,18 @@ func (in *SSHKey) DeepCopy() *SSHKey {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetSecretSpec) DeepCopyInto(out *TargetSecretSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetSecretSpec.
+func (in *TargetSecretSpec) DeepCopy() *TargetSecretSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetSecretSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -41,6 +41,7 @@ func Execute() {
 
 func main() {
 	rootCmd.AddCommand(uploadSSHKey)
+	rootCmd.AddCommand(quarantineCmd)
 	err := rootCmd.Execute()
 	if err != nil {
 		fmt.Println(err)
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/device"
+	"github.com/spf13/cobra"
+)
+
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "Manage devices with the permanent error tag",
+}
+
+var quarantineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the devices with the permanent error tag and the reason",
+	Run:   runQuarantineList,
+	Args:  cobra.NoArgs,
+}
+
+var quarantineClearCmd = &cobra.Command{
+	Use:   "clear DEVICE_ID [DEVICE_ID...]",
+	Short: "Removes the permanent error tag of devices which pass a health check",
+	Run:   runQuarantineClear,
+	Args:  cobra.MinimumNArgs(1),
+}
+
+// quarantineCluster is the name of the HivelocityCluster whose quarantined devices are managed.
+var quarantineCluster string
+
+func init() {
+	quarantineCmd.PersistentFlags().StringVar(&quarantineCluster, "cluster", "",
+		"Name of the HivelocityCluster which quarantined the devices")
+	if err := quarantineCmd.MarkPersistentFlagRequired("cluster"); err != nil {
+		panic(err)
+	}
+	quarantineCmd.AddCommand(quarantineListCmd, quarantineClearCmd)
+}
+
+func newHVClient() hvclient.Client {
+	apiKey := os.Getenv("HIVELOCITY_API_KEY")
+	if apiKey == "" {
+		fmt.Println("Missing environment variable HIVELOCITY_API_KEY")
+		os.Exit(1)
+	}
+	factory := hvclient.HivelocityFactory{}
+	return factory.NewClient(apiKey)
+}
+
+func runQuarantineList(_ *cobra.Command, _ []string) {
+	devices, err := device.ListQuarantinedDevices(context.Background(), newHVClient(), quarantineCluster)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(devices) == 0 {
+		fmt.Println("No quarantined devices found.")
+		return
+	}
+	for _, d := range devices {
+		since := "unknown"
+		if d.Since != nil {
+			since = d.Since.UTC().Format(time.RFC3339)
+		}
+		fmt.Printf("%d\treason: %s\tsince: %s\n", d.DeviceID, d.Reason, since)
+	}
+}
+
+func runQuarantineClear(_ *cobra.Command, args []string) {
+	hvClient := newHVClient()
+	failed := false
+	for _, arg := range args {
+		deviceID, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil {
+			fmt.Printf("Invalid device ID %q: %s\n", arg, err)
+			failed = true
+			continue
+		}
+		if err := device.ClearPermanentError(context.Background(), hvClient, quarantineCluster, int32(deviceID)); err != nil {
+			fmt.Println(err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Device %d passed the health check. The permanent error tag was removed.\n", deviceID)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
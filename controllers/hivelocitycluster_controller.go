@@ -18,7 +18,10 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -30,6 +33,7 @@ import (
 	secretutil "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/secrets"
 	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/device"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/dns"
 	certificatesv1 "k8s.io/api/certificates/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -59,7 +63,17 @@ import (
 
 const (
 	secretErrorRetryDelay = time.Second * 10
-	rateLimitWaitTime     = 5 * time.Minute
+
+	// rateLimitWaitTime is only a backstop. The rate limiter of the Hivelocity client already waits for
+	// Retry-After and X-RateLimit-Reset, so a rate limit error should be rare.
+	rateLimitWaitTime = time.Minute
+
+	// apiKeyRejectedRetryDelay is the delay before keys which the API rejected are validated again.
+	// A change of the secret triggers a reconcile anyway, but keys can also be re-enabled in the portal.
+	apiKeyRejectedRetryDelay = time.Minute
+
+	// apiKeyValidationInterval is the time after which an accepted or rejected API key gets validated again.
+	apiKeyValidationInterval = 5 * time.Minute
 )
 
 // HivelocityClusterReconciler reconciles a HivelocityCluster object.
@@ -72,9 +86,20 @@ type HivelocityClusterReconciler struct {
 	APIReader       client.Reader
 	HVClientFactory hvclient.Factory
 
+	// SupportTickets enables opening support tickets for quarantined devices.
+	SupportTickets bool
+
+	// DefaultAPIKey is used if the HivelocitySecret of a cluster does not exist and the cluster opted in with UseDefaultCredential.
+	DefaultAPIKey *secretutil.DefaultAPIKey
+
+	// TargetClusterClients receives the cached clients of the target cluster managers.
+	TargetClusterClients *TargetClusterClients
+
 	targetClusterManagersStopCh    map[types.NamespacedName]chan struct{}
 	targetClusterManagersLock      sync.Mutex
 	TargetClusterManagersWaitGroup *sync.WaitGroup
+
+	apiKeyValidator apiKeyValidator
 }
 
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
@@ -127,11 +152,23 @@ func (r *HivelocityClusterReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	// Create the scope.
 	secretManager := secretutil.NewSecretManager(logger, r.Client, r.APIReader)
-	apiKey, hvSecret, err := getAndValidateHivelocityAPIKey(ctx, req.Namespace, hvCluster, secretManager)
+	apiKey, hvSecret, err := getAndValidateHivelocityAPIKey(ctx, req.Namespace, hvCluster, secretManager, r.DefaultAPIKey)
 	if err != nil {
 		return hvAPIKeyErrorResult(ctx, err, hvCluster, infrav1.CredentialsAvailableCondition, r.Client)
 	}
 
+	activeAPIKey, secretKey, err := r.selectHivelocityAPIKey(ctx, hvCluster, hvSecret, apiKey)
+	var rejectedErr *secretutil.HivelocityAPIKeyRejectedError
+	switch {
+	case err == nil:
+		apiKey = activeAPIKey
+	case errors.As(err, &rejectedErr) && !hvCluster.DeletionTimestamp.IsZero():
+		// The cluster has to be deletable, even if the API rejects all keys, e.g. because they were revoked.
+		logger.Info("Hivelocity API rejected all API keys. Deleting the cluster anyway", "err", err.Error())
+	default:
+		return hvAPIKeyErrorResult(ctx, err, hvCluster, infrav1.CredentialsAvailableCondition, r.Client)
+	}
+
 	hvClient := r.HVClientFactory.NewClient(apiKey)
 
 	clusterScope, err := scope.NewClusterScope(ctx, scope.ClusterScopeParams{
@@ -154,6 +191,18 @@ func (r *HivelocityClusterReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		}
 	}()
 
+	if rejectedErr != nil {
+		conditions.MarkFalse(
+			hvCluster,
+			infrav1.CredentialsAvailableCondition,
+			infrav1.HivelocityWrongAPIKeyReason,
+			clusterv1.ConditionSeverityError,
+			rejectedErr.Error(),
+		)
+	} else {
+		setActiveSecretKey(hvCluster, secretKey)
+	}
+
 	// check whether rate limit has been reached and if so, then wait.
 	if wait := reconcileRateLimit(hvCluster); wait {
 		// don't wait too long. Otherwise: context canceled
@@ -204,9 +253,17 @@ func (r *HivelocityClusterReconciler) reconcileNormal(ctx context.Context, clust
 			return ctrl.Result{}, fmt.Errorf("device.GetFirstFreeDevice() found no device: %+v (%s)", hmt.Spec.Template.Spec.DeviceSelector,
 				reason)
 		}
-		logger.Info(fmt.Sprintf("Setting hvCluster.Spec.ControlPlaneEndpoint.Host to %q", hvDevice.PrimaryIp))
+		host := hvDevice.PrimaryIp
+		if dnsSpec := hvCluster.Spec.DNS; dnsSpec != nil {
+			// The records point to the first control plane until the control planes are ready.
+			if err := dns.ReconcileAddressRecords(ctx, clusterScope.HVClient, dnsSpec.Zone, dnsSpec.APIEndpointFQDN(), dnsSpec.TTL, []string{hvDevice.PrimaryIp}); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to create DNS records for the control plane endpoint: %w", err)
+			}
+			host = dnsSpec.APIEndpointFQDN()
+		}
+		logger.Info(fmt.Sprintf("Setting hvCluster.Spec.ControlPlaneEndpoint.Host to %q", host))
 
-		hvCluster.Spec.ControlPlaneEndpoint.Host = hvDevice.PrimaryIp
+		hvCluster.Spec.ControlPlaneEndpoint.Host = host
 		hvCluster.Spec.ControlPlaneEndpoint.Port = 6443
 	}
 
@@ -214,6 +271,20 @@ func (r *HivelocityClusterReconciler) reconcileNormal(ctx context.Context, clust
 
 	hvCluster.Status.Ready = true
 
+	if err := reconcileDNS(ctx, clusterScope); err != nil {
+		reterr := fmt.Errorf("failed to reconcile DNS records: %w", err)
+		conditions.MarkFalse(
+			hvCluster,
+			infrav1.DNSRecordsReadyCondition,
+			infrav1.DNSRecordsReconcileFailedReason,
+			clusterv1.ConditionSeverityWarning,
+			reterr.Error(),
+		)
+		return reconcile.Result{}, reterr
+	}
+
+	reconcileQuarantinedDevices(ctx, clusterScope, r.SupportTickets)
+
 	result, err := r.reconcileTargetClusterManager(ctx, clusterScope)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("failed to reconcile target cluster manager: %w", err)
@@ -224,6 +295,14 @@ func (r *HivelocityClusterReconciler) reconcileNormal(ctx context.Context, clust
 
 	conditions.MarkTrue(hvCluster, infrav1.TargetClusterReadyCondition)
 
+	if targetSecret := hvCluster.Spec.TargetSecret; (targetSecret != nil && targetSecret.Disabled) || hvCluster.Status.ActiveSecretKey == "" {
+		// The copy is disabled, or there is no HivelocitySecret, because the default credential of the manager
+		// is in use. The default credential is never copied into workload clusters.
+		conditions.Delete(hvCluster, infrav1.TargetClusterSecretReadyCondition)
+		logger.V(1).Info("Reconciling finished")
+		return reconcile.Result{}, nil
+	}
+
 	if err = reconcileTargetSecret(ctx, clusterScope); err != nil {
 		reterr := fmt.Errorf("failed to reconcile target secret: %w", err)
 		conditions.MarkFalse(
@@ -270,10 +349,22 @@ func (r *HivelocityClusterReconciler) reconcileDelete(ctx context.Context, clust
 		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
 	}
 
-	secretManager := secretutil.NewSecretManager(log, r.Client, r.APIReader)
-	// Remove finalizer of secret
-	if err := secretManager.ReleaseSecret(ctx, hvSecret); err != nil {
-		return reconcile.Result{}, fmt.Errorf("failed to release HivelocitySecret: %w", err)
+	if dnsSpec := hvCluster.Spec.DNS; dnsSpec != nil {
+		if conditions.GetReason(hvCluster, infrav1.CredentialsAvailableCondition) == infrav1.HivelocityWrongAPIKeyReason {
+			// the records cannot be deleted without an API key which the API accepts.
+			record.Warnf(hvCluster, "DNSRecordsNotDeleted",
+				"Hivelocity API rejected the API keys. Delete the DNS records of %q manually", dnsSpec.APIEndpointFQDN())
+		} else if err := dns.DeleteAddressRecords(ctx, clusterScope.HVClient, dnsSpec.Zone, dnsSpec.APIEndpointFQDN()); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to delete DNS records of the control plane endpoint: %w", err)
+		}
+	}
+
+	// Remove finalizer of secret. There is no secret if the default credential of the manager is in use.
+	if hvSecret != nil {
+		secretManager := secretutil.NewSecretManager(log, r.Client, r.APIReader)
+		if err := secretManager.ReleaseSecret(ctx, hvSecret); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to release HivelocitySecret: %w", err)
+		}
 	}
 
 	// Stop CSR manager
@@ -295,6 +386,118 @@ func (r *HivelocityClusterReconciler) reconcileDelete(ctx context.Context, clust
 	return reconcile.Result{}, nil
 }
 
+// reconcileQuarantinedDevices clears the permanent error of the devices in the ClearPermanentErrorAnnotation,
+// and lists the devices which still have the permanent error tag in the status. Only devices which were
+// quarantined by this cluster are listed and cleared. With supportTickets, a support
+// ticket gets opened for each of them. Errors are reported as events, because they must not block the
+// reconciliation of the cluster.
+func reconcileQuarantinedDevices(ctx context.Context, clusterScope *scope.ClusterScope, supportTickets bool) {
+	hvCluster := clusterScope.HivelocityCluster
+
+	if value, ok := hvCluster.Annotations[infrav1.ClearPermanentErrorAnnotation]; ok {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			deviceID, err := strconv.ParseInt(field, 10, 32)
+			if err != nil {
+				record.Warnf(hvCluster, "ClearPermanentErrorFailed", "Invalid device ID %q in annotation %s",
+					field, infrav1.ClearPermanentErrorAnnotation)
+				continue
+			}
+			if err := device.ClearPermanentError(ctx, clusterScope.HVClient, hvCluster.Name, int32(deviceID)); err != nil {
+				record.Warnf(hvCluster, "ClearPermanentErrorFailed", "Failed to clear permanent error: %s", err)
+				continue
+			}
+			record.Eventf(hvCluster, "PermanentErrorCleared",
+				"Device %d passed the health check. The permanent error tag was removed", deviceID)
+		}
+		delete(hvCluster.Annotations, infrav1.ClearPermanentErrorAnnotation)
+	}
+
+	quarantined, err := device.ListQuarantinedDevices(ctx, clusterScope.HVClient, hvCluster.Name)
+	if err != nil {
+		clusterScope.Error(err, "failed to list quarantined devices")
+		return
+	}
+	if supportTickets {
+		quarantined = reconcileSupportTickets(ctx, clusterScope, quarantined)
+	}
+	hvCluster.Status.QuarantinedDevices = quarantined
+}
+
+// reconcileSupportTickets opens and watches the support tickets of the devices which the cluster quarantined. It
+// returns the devices which are still quarantined.
+func reconcileSupportTickets(ctx context.Context, clusterScope *scope.ClusterScope, quarantined []infrav1.QuarantinedDevice) []infrav1.QuarantinedDevice {
+	hvCluster := clusterScope.HivelocityCluster
+	remaining := make([]infrav1.QuarantinedDevice, 0, len(quarantined))
+	for _, d := range quarantined {
+		state, ticketID, err := device.ReconcileSupportTicket(ctx, clusterScope.HVClient, hvCluster.Name, d.DeviceID)
+		if state == device.SupportTicketUnhealthy {
+			record.Warnf(hvCluster, "SupportTicketUnhealthy",
+				"Support ticket %d was resolved, but device %d is still unhealthy. A follow-up ticket will be opened: %s",
+				ticketID, d.DeviceID, err)
+			d.TicketID = 0
+			remaining = append(remaining, d)
+			continue
+		}
+		if err != nil {
+			record.Warnf(hvCluster, "SupportTicketFailed", "Failed to reconcile support ticket of device %d: %s", d.DeviceID, err)
+			remaining = append(remaining, d)
+			continue
+		}
+		switch state {
+		case device.SupportTicketOpened:
+			record.Eventf(hvCluster, "SupportTicketOpened", "Opened support ticket %d for quarantined device %d", ticketID, d.DeviceID)
+		case device.SupportTicketResolved:
+			record.Eventf(hvCluster, "PermanentErrorCleared",
+				"Support ticket %d was resolved and device %d passed the health check. The permanent error tag was removed",
+				ticketID, d.DeviceID)
+			continue
+		}
+		d.TicketID = ticketID
+		remaining = append(remaining, d)
+	}
+	return remaining
+}
+
+// reconcileDNS points the address records of the control plane endpoint to all healthy control planes.
+// If no control plane is healthy, the existing records are kept.
+func reconcileDNS(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	hvCluster := clusterScope.HivelocityCluster
+	dnsSpec := hvCluster.Spec.DNS
+	if dnsSpec == nil {
+		conditions.Delete(hvCluster, infrav1.DNSRecordsReadyCondition)
+		return nil
+	}
+
+	machines, hvMachines, err := clusterScope.ListMachines(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	addresses := dns.HealthyControlPlaneAddresses(machines, hvMachines)
+	if len(addresses) == 0 {
+		conditions.MarkFalse(
+			hvCluster,
+			infrav1.DNSRecordsReadyCondition,
+			infrav1.NoHealthyControlPlaneReason,
+			clusterv1.ConditionSeverityInfo,
+			"no healthy control plane found, keeping existing records of %q",
+			dnsSpec.APIEndpointFQDN(),
+		)
+		return nil
+	}
+
+	if err := dns.ReconcileAddressRecords(ctx, clusterScope.HVClient, dnsSpec.Zone, dnsSpec.APIEndpointFQDN(), dnsSpec.TTL, addresses); err != nil {
+		return err
+	}
+
+	conditions.MarkTrue(hvCluster, infrav1.DNSRecordsReadyCondition)
+	return nil
+}
+
 // reconcileRateLimit checks whether a rate limit has been reached and returns whether
 // the controller should wait a bit more.
 func reconcileRateLimit(setter conditions.Setter) bool {
@@ -312,7 +515,16 @@ func reconcileRateLimit(setter conditions.Setter) bool {
 	return false
 }
 
-func getAndValidateHivelocityAPIKey(ctx context.Context, namespace string, hvCluster *infrav1.HivelocityCluster, secretManager *secretutil.SecretManager) (string, *corev1.Secret, error) {
+// getAndValidateHivelocityAPIKey returns the API key of the cluster and the HivelocitySecret.
+// If the secret does not exist, the cluster opted in with UseDefaultCredential and a default credential is
+// configured, its API key is returned without a secret.
+func getAndValidateHivelocityAPIKey(
+	ctx context.Context,
+	namespace string,
+	hvCluster *infrav1.HivelocityCluster,
+	secretManager *secretutil.SecretManager,
+	defaultAPIKey *secretutil.DefaultAPIKey,
+) (string, *corev1.Secret, error) {
 	// retrieve Hivelocity secret
 	secretNamspacedName := types.NamespacedName{Namespace: namespace, Name: hvCluster.Spec.HivelocitySecret.Name}
 
@@ -324,6 +536,10 @@ func getAndValidateHivelocityAPIKey(ctx context.Context, namespace string, hvClu
 		hvCluster.DeletionTimestamp.IsZero(),
 	)
 	if err != nil {
+		if apierrors.IsNotFound(err) && hvCluster.Spec.HivelocitySecret.UseDefaultCredential && defaultAPIKey.IsSet() {
+			apiKey, err := defaultAPIKey.APIKey(ctx)
+			return apiKey, nil, err
+		}
 		if apierrors.IsNotFound(err) {
 			return "", nil, &secretutil.ResolveSecretRefError{
 				Message: fmt.Sprintf("The Hivelocity secret %s does not exist", secretNamspacedName),
@@ -332,9 +548,8 @@ func getAndValidateHivelocityAPIKey(ctx context.Context, namespace string, hvClu
 		return "", nil, err
 	}
 
-	apiKey := string(hvSecret.Data[hvCluster.Spec.HivelocitySecret.Key])
-
 	// Validate apiKey
+	apiKey, _ := hivelocityAPIKeyFromSecret(hvCluster, hvSecret)
 	if apiKey == "" {
 		return "", nil, &secretutil.HivelocityAPIKeyValidationError{}
 	}
@@ -342,6 +557,148 @@ func getAndValidateHivelocityAPIKey(ctx context.Context, namespace string, hvClu
 	return apiKey, hvSecret, nil
 }
 
+// hivelocityAPIKeyFromSecret returns the API key which gets used and its key in the secret.
+// That is the secondary key if the cluster controller switched to it, because the API rejected the primary key.
+// Otherwise the primary key is preferred. Empty keys are skipped, so that either key can be removed during a
+// rotation. The returned API key is empty if no key is set.
+func hivelocityAPIKeyFromSecret(hvCluster *infrav1.HivelocityCluster, hvSecret *corev1.Secret) (apiKey, secretKey string) {
+	for _, secretKey := range hivelocitySecretKeys(hvCluster) {
+		if apiKey := string(hvSecret.Data[secretKey]); apiKey != "" {
+			return apiKey, secretKey
+		}
+	}
+	return "", ""
+}
+
+// hivelocitySecretKeys returns the keys of the API keys in the HivelocitySecret in the order of preference.
+func hivelocitySecretKeys(hvCluster *infrav1.HivelocityCluster) []string {
+	ref := hvCluster.Spec.HivelocitySecret
+	if ref.SecondaryKey == "" || ref.SecondaryKey == ref.Key {
+		return []string{ref.Key}
+	}
+	if hvCluster.Status.ActiveSecretKey == ref.SecondaryKey {
+		return []string{ref.SecondaryKey, ref.Key}
+	}
+	return []string{ref.Key, ref.SecondaryKey}
+}
+
+// selectHivelocityAPIKey returns the API key which the Hivelocity API accepts and its key in the secret.
+// The key of the HivelocitySecret is preferred. The secondary key is only used if the API rejects it,
+// so that API keys can be rotated without downtime.
+// Without secret, the default API key of the manager gets validated and the returned key in the secret is empty.
+func (r *HivelocityClusterReconciler) selectHivelocityAPIKey(
+	ctx context.Context,
+	hvCluster *infrav1.HivelocityCluster,
+	hvSecret *corev1.Secret,
+	defaultAPIKey string,
+) (apiKey, secretKey string, err error) {
+	if hvSecret == nil {
+		err := r.apiKeyValidator.validate(ctx, r.HVClientFactory, defaultAPIKey)
+		if errors.Is(err, hvclient.ErrInvalidAPIKey) {
+			return "", "", &secretutil.HivelocityAPIKeyRejectedError{}
+		}
+		return defaultAPIKey, "", nil
+	}
+
+	ref := hvCluster.Spec.HivelocitySecret
+	secretKeys := []string{ref.Key}
+	if ref.SecondaryKey != "" && ref.SecondaryKey != ref.Key {
+		secretKeys = append(secretKeys, ref.SecondaryKey)
+	}
+
+	var rejected []string
+	for _, secretKey := range secretKeys {
+		apiKey := string(hvSecret.Data[secretKey])
+		if apiKey == "" {
+			continue
+		}
+		err := r.apiKeyValidator.validate(ctx, r.HVClientFactory, apiKey)
+		if errors.Is(err, hvclient.ErrInvalidAPIKey) {
+			rejected = append(rejected, secretKey)
+			continue
+		}
+		if err != nil {
+			// The key could not be validated, e.g. because the API is not reachable. Use it anyway,
+			// the reconcile runs into the same error and retries.
+			log.FromContext(ctx).Info("Failed to validate Hivelocity API key", "secretKey", secretKey, "err", err)
+		}
+		return apiKey, secretKey, nil
+	}
+	return "", "", &secretutil.HivelocityAPIKeyRejectedError{SecretKeys: rejected}
+}
+
+// setActiveSecretKey records the key in the secret of the API key in use, so that the other controllers use the same key.
+func setActiveSecretKey(hvCluster *infrav1.HivelocityCluster, secretKey string) {
+	if conditions.GetReason(hvCluster, infrav1.CredentialsAvailableCondition) == infrav1.HivelocityWrongAPIKeyReason {
+		conditions.MarkTrue(hvCluster, infrav1.CredentialsAvailableCondition)
+	}
+
+	if hvCluster.Status.ActiveSecretKey == secretKey {
+		return
+	}
+	switch secretKey {
+	case "":
+		record.Eventf(hvCluster, "DefaultAPIKeyInUse", "Secret %s does not exist. Using the default API key of the manager",
+			hvCluster.Spec.HivelocitySecret.Name)
+	case hvCluster.Spec.HivelocitySecret.Key:
+		record.Eventf(hvCluster, "PrimaryAPIKeyInUse", "Using the API key of %s in secret %s",
+			secretKey, hvCluster.Spec.HivelocitySecret.Name)
+	default:
+		record.Warnf(hvCluster, "SecondaryAPIKeyInUse", "Hivelocity API rejected the API key of %s in secret %s. Using %s",
+			hvCluster.Spec.HivelocitySecret.Key, hvCluster.Spec.HivelocitySecret.Name, secretKey)
+	}
+	hvCluster.Status.ActiveSecretKey = secretKey
+}
+
+// apiKeyValidator validates API keys with the Hivelocity API. The results are cached for apiKeyValidationInterval,
+// so that a reconcile does not cost an additional API call, and a rejected key is not checked on every reconcile
+// while the keys get rotated.
+type apiKeyValidator struct {
+	mu      sync.Mutex
+	results map[[sha256.Size]byte]apiKeyValidation
+}
+
+// apiKeyValidation is the cached result of a validation.
+type apiKeyValidation struct {
+	checkedAt time.Time
+	rejected  bool
+}
+
+// validate returns hvclient.ErrInvalidAPIKey if the API rejects the key. The state of a rejected key is dropped from
+// the factory, because the device inventory and the rate limiter of its clients are of no use anymore.
+func (v *apiKeyValidator) validate(ctx context.Context, factory hvclient.Factory, apiKey string) error {
+	hash := sha256.Sum256([]byte(apiKey))
+
+	v.mu.Lock()
+	result, ok := v.results[hash]
+	v.mu.Unlock()
+	if ok && time.Since(result.checkedAt) < apiKeyValidationInterval {
+		if result.rejected {
+			return hvclient.ErrInvalidAPIKey
+		}
+		return nil
+	}
+
+	err := factory.NewClient(apiKey).ValidateAPIKey(ctx)
+	rejected := errors.Is(err, hvclient.ErrInvalidAPIKey)
+	if rejected {
+		factory.Forget(apiKey)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.results == nil {
+		v.results = make(map[[sha256.Size]byte]apiKeyValidation)
+	}
+	if err != nil && !rejected {
+		// the key could not be validated, e.g. because the API is not reachable.
+		delete(v.results, hash)
+		return err
+	}
+	v.results[hash] = apiKeyValidation{checkedAt: time.Now(), rejected: rejected}
+	return err
+}
+
 func hvAPIKeyErrorResult(
 	ctx context.Context,
 	err error,
@@ -371,6 +728,15 @@ func hvAPIKeyErrorResult(
 			"invalid or not specified credentials for Hivelocity in secret",
 		)
 
+	case *secretutil.HivelocityAPIKeyRejectedError:
+		conditions.MarkFalse(setter,
+			conditionType,
+			infrav1.HivelocityWrongAPIKeyReason,
+			clusterv1.ConditionSeverityError,
+			err.Error(),
+		)
+		res = ctrl.Result{RequeueAfter: apiKeyRejectedRetryDelay}
+
 	default:
 		return ctrl.Result{}, fmt.Errorf("an unhandled failure occurred with the Hivelocity secret: %w", err)
 	}
@@ -417,21 +783,6 @@ func reconcileTargetSecret(ctx context.Context, clusterScope *scope.ClusterScope
 	targetNS := metav1.NamespaceSystem
 	sourceNS := clusterScope.HivelocityCluster.Namespace
 
-	_, err = targetClientSet.CoreV1().Secrets(targetNS).Get(
-		ctx,
-		secretName,
-		metav1.GetOptions{},
-	)
-
-	if err == nil {
-		// Secret exists. Nothing to do.
-		return nil
-	}
-
-	if !apierrors.IsNotFound(err) {
-		return fmt.Errorf("failed to get secret: %w", err)
-	}
-
 	apiKeySecretName := types.NamespacedName{
 		Namespace: sourceNS,
 		Name:      secretName,
@@ -442,26 +793,38 @@ func reconcileTargetSecret(ctx context.Context, clusterScope *scope.ClusterScope
 		return fmt.Errorf("failed to acquire secret: %w", err)
 	}
 
-	key := clusterScope.HivelocityCluster.Spec.HivelocitySecret.Key
-
-	apiKey, keyExists := apiKeySecret.Data[key]
-	if !keyExists {
-		return fmt.Errorf(
-			"error key %s does not exist in secret/%s: %w",
-			key,
-			apiKeySecretName,
-			err,
-		)
+	data, err := targetSecretData(clusterScope.HivelocityCluster, apiKeySecret)
+	if err != nil {
+		return fmt.Errorf("failed to get data of secret/%s: %w", apiKeySecretName, err)
 	}
 
-	var immutable bool
-	data := make(map[string][]byte)
-	data[key] = apiKey
+	existingSecret, err := targetClientSet.CoreV1().Secrets(targetNS).Get(
+		ctx,
+		secretName,
+		metav1.GetOptions{},
+	)
 
-	// Save api server information
-	data["apiserver-host"] = []byte(clusterScope.HivelocityCluster.Spec.ControlPlaneEndpoint.Host)
-	data["apiserver-port"] = []byte(strconv.Itoa(int(clusterScope.HivelocityCluster.Spec.ControlPlaneEndpoint.Port)))
+	if err == nil {
+		// Secret exists. Update the API key if another key of the HivelocitySecret is in use now.
+		key := clusterScope.HivelocityCluster.Spec.HivelocitySecret.Key
+		if bytes.Equal(existingSecret.Data[key], data[key]) {
+			return nil
+		}
+		if existingSecret.Data == nil {
+			existingSecret.Data = make(map[string][]byte)
+		}
+		existingSecret.Data[key] = data[key]
+		if _, err := targetClientSet.CoreV1().Secrets(targetNS).Update(ctx, existingSecret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update secret: %w", err)
+		}
+		return nil
+	}
 
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	var immutable bool
 	newSecret := corev1.Secret{
 		Immutable: &immutable,
 		Data:      data,
@@ -480,6 +843,34 @@ func reconcileTargetSecret(ctx context.Context, clusterScope *scope.ClusterScope
 	return nil
 }
 
+// targetSecretData returns the data of the secret in the workload cluster. It contains the API key which is in use,
+// or the one of TargetSecret.Key, under the key of the HivelocitySecret, and the address of the API server.
+func targetSecretData(hvCluster *infrav1.HivelocityCluster, apiKeySecret *corev1.Secret) (map[string][]byte, error) {
+	key := hvCluster.Spec.HivelocitySecret.Key
+
+	sourceKey := hvCluster.Status.ActiveSecretKey
+	if sourceKey == "" {
+		sourceKey = key
+	}
+	// The workload cluster may get another API key with fewer permissions. It is stored under the usual key.
+	if targetSecret := hvCluster.Spec.TargetSecret; targetSecret != nil && targetSecret.Key != "" {
+		sourceKey = targetSecret.Key
+	}
+
+	apiKey, keyExists := apiKeySecret.Data[sourceKey]
+	if !keyExists {
+		return nil, fmt.Errorf("key %s does not exist", sourceKey)
+	}
+
+	data := make(map[string][]byte)
+	data[key] = apiKey
+
+	// Save api server information
+	data["apiserver-host"] = []byte(hvCluster.Spec.ControlPlaneEndpoint.Host)
+	data["apiserver-port"] = []byte(strconv.Itoa(int(hvCluster.Spec.ControlPlaneEndpoint.Port)))
+	return data, nil
+}
+
 func (r *HivelocityClusterReconciler) reconcileTargetClusterManager(ctx context.Context, clusterScope *scope.ClusterScope) (res reconcile.Result, err error) {
 	r.targetClusterManagersLock.Lock()
 	defer r.targetClusterManagersLock.Unlock()
@@ -513,6 +904,7 @@ func (r *HivelocityClusterReconciler) reconcileTargetClusterManager(ctx context.
 		}
 
 		r.targetClusterManagersStopCh[key] = make(chan struct{})
+		r.TargetClusterClients.set(key, m.GetClient())
 
 		ctx, cancel := context.WithCancel(ctx)
 
@@ -537,6 +929,7 @@ func (r *HivelocityClusterReconciler) reconcileTargetClusterManager(ctx context.
 			r.targetClusterManagersLock.Lock()
 			defer r.targetClusterManagersLock.Unlock()
 			delete(r.targetClusterManagersStopCh, key)
+			r.TargetClusterClients.delete(key)
 		}()
 
 		// Cancel when stop channel received input
@@ -601,6 +994,7 @@ func (r *HivelocityClusterReconciler) newTargetClusterManager(ctx context.Contex
 	}
 
 	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
 	_ = certificatesv1.AddToScheme(scheme)
 	_ = infrav1.AddToScheme(scheme)
 
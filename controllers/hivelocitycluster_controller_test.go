@@ -17,21 +17,32 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	secretutil "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/secrets"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/utils"
 	hv "github.com/hivelocity/hivelocity-client-go/client"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("Hivelocity ClusterReconciler", func() {
@@ -485,5 +496,321 @@ var _ = Describe("Hivelocity secret", func() {
 				"wrongkey": []byte("my-api-key"),
 			},
 		}, infrav1.HivelocityCredentialsInvalidReason),
+		Entry("rejected hivelocity api key", corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hv-secret",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				"HIVELOCITY_API_KEY": []byte(mock.InvalidAPIKey),
+			},
+		}, infrav1.HivelocityWrongAPIKeyReason),
 	)
 })
+
+func Test_selectHivelocityAPIKey(t *testing.T) {
+	hvCluster := &infrav1.HivelocityCluster{
+		Spec: infrav1.HivelocityClusterSpec{
+			HivelocitySecret: infrav1.HivelocitySecretRef{Name: "hv-secret", Key: "HIVELOCITY_API_KEY", SecondaryKey: "HIVELOCITY_API_KEY_NEW"},
+		},
+	}
+	r := &HivelocityClusterReconciler{HVClientFactory: mock.NewMockedHVClientFactory()}
+
+	for _, tc := range []struct {
+		name          string
+		data          map[string][]byte
+		defaultAPIKey string
+		wantSecretKey string
+		wantErr       bool
+	}{
+		{
+			name:          "primary key accepted",
+			data:          map[string][]byte{"HIVELOCITY_API_KEY": []byte("old-key"), "HIVELOCITY_API_KEY_NEW": []byte("new-key")},
+			wantSecretKey: "HIVELOCITY_API_KEY",
+		},
+		{
+			name:          "primary key rejected",
+			data:          map[string][]byte{"HIVELOCITY_API_KEY": []byte(mock.InvalidAPIKey), "HIVELOCITY_API_KEY_NEW": []byte("new-key")},
+			wantSecretKey: "HIVELOCITY_API_KEY_NEW",
+		},
+		{
+			name:          "primary key empty",
+			data:          map[string][]byte{"HIVELOCITY_API_KEY": []byte(""), "HIVELOCITY_API_KEY_NEW": []byte("new-key")},
+			wantSecretKey: "HIVELOCITY_API_KEY_NEW",
+		},
+		{
+			name:    "all keys rejected",
+			data:    map[string][]byte{"HIVELOCITY_API_KEY": []byte(mock.InvalidAPIKey)},
+			wantErr: true,
+		},
+		{
+			name:          "default credential accepted",
+			defaultAPIKey: "default-key",
+		},
+		{
+			name:          "default credential rejected",
+			defaultAPIKey: mock.InvalidAPIKey,
+			wantErr:       true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var hvSecret *corev1.Secret
+			if tc.data != nil {
+				hvSecret = &corev1.Secret{Data: tc.data}
+			}
+			apiKey, secretKey, err := r.selectHivelocityAPIKey(context.Background(), hvCluster, hvSecret, tc.defaultAPIKey)
+			if tc.wantErr {
+				var rejectedErr *secretutil.HivelocityAPIKeyRejectedError
+				require.ErrorAs(t, err, &rejectedErr)
+				if hvSecret != nil {
+					require.Equal(t, []string{"HIVELOCITY_API_KEY"}, rejectedErr.SecretKeys)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantSecretKey, secretKey)
+			if hvSecret == nil {
+				require.Equal(t, tc.defaultAPIKey, apiKey)
+				return
+			}
+			require.Equal(t, string(tc.data[secretKey]), apiKey)
+		})
+	}
+}
+
+func Test_hivelocityAPIKeyFromSecret(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		data            map[string][]byte
+		activeSecretKey string
+		wantSecretKey   string
+	}{
+		{
+			name:          "primary key",
+			data:          map[string][]byte{"HIVELOCITY_API_KEY": []byte("old-key"), "HIVELOCITY_API_KEY_NEW": []byte("new-key")},
+			wantSecretKey: "HIVELOCITY_API_KEY",
+		},
+		{
+			name:            "active secondary key",
+			data:            map[string][]byte{"HIVELOCITY_API_KEY": []byte("old-key"), "HIVELOCITY_API_KEY_NEW": []byte("new-key")},
+			activeSecretKey: "HIVELOCITY_API_KEY_NEW",
+			wantSecretKey:   "HIVELOCITY_API_KEY_NEW",
+		},
+		{
+			name:          "empty primary key",
+			data:          map[string][]byte{"HIVELOCITY_API_KEY_NEW": []byte("new-key")},
+			wantSecretKey: "HIVELOCITY_API_KEY_NEW",
+		},
+		{
+			name:            "empty active secondary key",
+			data:            map[string][]byte{"HIVELOCITY_API_KEY": []byte("old-key"), "HIVELOCITY_API_KEY_NEW": []byte("")},
+			activeSecretKey: "HIVELOCITY_API_KEY_NEW",
+			wantSecretKey:   "HIVELOCITY_API_KEY",
+		},
+		{
+			name: "no key",
+			data: map[string][]byte{"HIVELOCITY_API_KEY": []byte("")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hvCluster := &infrav1.HivelocityCluster{
+				Spec: infrav1.HivelocityClusterSpec{
+					HivelocitySecret: infrav1.HivelocitySecretRef{Name: "hv-secret", Key: "HIVELOCITY_API_KEY", SecondaryKey: "HIVELOCITY_API_KEY_NEW"},
+				},
+				Status: infrav1.HivelocityClusterStatus{ActiveSecretKey: tc.activeSecretKey},
+			}
+			apiKey, secretKey := hivelocityAPIKeyFromSecret(hvCluster, &corev1.Secret{Data: tc.data})
+			require.Equal(t, tc.wantSecretKey, secretKey)
+			require.Equal(t, string(tc.data[tc.wantSecretKey]), apiKey)
+		})
+	}
+}
+
+func Test_targetSecretData(t *testing.T) {
+	hvCluster := &infrav1.HivelocityCluster{
+		Spec: infrav1.HivelocityClusterSpec{
+			ControlPlaneEndpoint: &clusterv1.APIEndpoint{Host: "192.0.2.1", Port: 6443},
+			HivelocitySecret:     infrav1.HivelocitySecretRef{Name: "hv-secret", Key: "HIVELOCITY_API_KEY", SecondaryKey: "HIVELOCITY_API_KEY_NEW"},
+		},
+	}
+	hvSecret := &corev1.Secret{Data: map[string][]byte{
+		"HIVELOCITY_API_KEY":          []byte("old-key"),
+		"HIVELOCITY_API_KEY_NEW":      []byte("new-key"),
+		"HIVELOCITY_API_KEY_WORKLOAD": []byte("workload-key"),
+	}}
+
+	data, err := targetSecretData(hvCluster, hvSecret)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{
+		"HIVELOCITY_API_KEY": []byte("old-key"),
+		"apiserver-host":     []byte("192.0.2.1"),
+		"apiserver-port":     []byte("6443"),
+	}, data)
+
+	// the active key gets copied under the primary key
+	hvCluster.Status.ActiveSecretKey = "HIVELOCITY_API_KEY_NEW"
+	data, err = targetSecretData(hvCluster, hvSecret)
+	require.NoError(t, err)
+	require.Equal(t, []byte("new-key"), data["HIVELOCITY_API_KEY"])
+
+	hvCluster.Spec.TargetSecret = &infrav1.TargetSecretSpec{Key: "HIVELOCITY_API_KEY_WORKLOAD"}
+	data, err = targetSecretData(hvCluster, hvSecret)
+	require.NoError(t, err)
+	require.Equal(t, []byte("workload-key"), data["HIVELOCITY_API_KEY"])
+
+	hvCluster.Spec.TargetSecret.Key = "unknown"
+	_, err = targetSecretData(hvCluster, hvSecret)
+	require.Error(t, err)
+}
+
+func Test_getAndValidateHivelocityAPIKey_defaultCredential(t *testing.T) {
+	defaultSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "caphv-system", Name: "hivelocity"},
+		Data:       map[string][]byte{"HIVELOCITY_API_KEY": []byte("default-key")},
+	}
+	reader := fake.NewClientBuilder().WithObjects(defaultSecret).Build()
+	secretManager := secretutil.NewSecretManager(logr.Discard(), reader, reader)
+	defaultAPIKey := secretutil.NewDefaultAPIKey("", client.ObjectKeyFromObject(defaultSecret), "HIVELOCITY_API_KEY", reader)
+
+	for _, tc := range []struct {
+		name                 string
+		useDefaultCredential bool
+		defaultAPIKey        *secretutil.DefaultAPIKey
+		wantAPIKey           string
+	}{
+		{
+			name:          "no opt-in",
+			defaultAPIKey: defaultAPIKey,
+		},
+		{
+			name:                 "opt-in",
+			useDefaultCredential: true,
+			defaultAPIKey:        defaultAPIKey,
+			wantAPIKey:           "default-key",
+		},
+		{
+			name:                 "opt-in without default credential",
+			useDefaultCredential: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hvCluster := &infrav1.HivelocityCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "hv-cluster"},
+				Spec: infrav1.HivelocityClusterSpec{
+					HivelocitySecret: infrav1.HivelocitySecretRef{
+						Name:                 "missing",
+						Key:                  "HIVELOCITY_API_KEY",
+						UseDefaultCredential: tc.useDefaultCredential,
+					},
+				},
+			}
+			apiKey, hvSecret, err := getAndValidateHivelocityAPIKey(context.Background(), "default", hvCluster, secretManager, tc.defaultAPIKey)
+			require.Nil(t, hvSecret)
+			if tc.wantAPIKey == "" {
+				require.ErrorAs(t, err, new(*secretutil.ResolveSecretRefError))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantAPIKey, apiKey)
+		})
+	}
+}
+
+// countingFactory counts the validations and forgotten keys of the mocked factory.
+type countingFactory struct {
+	hvclient.Factory
+	validations int
+	forgotten   int
+}
+
+func (f *countingFactory) NewClient(hvAPIKey string) hvclient.Client {
+	return &countingClient{Client: f.Factory.NewClient(hvAPIKey), factory: f}
+}
+
+func (f *countingFactory) Forget(hvAPIKey string) {
+	f.forgotten++
+	f.Factory.Forget(hvAPIKey)
+}
+
+type countingClient struct {
+	hvclient.Client
+	factory *countingFactory
+}
+
+func (c *countingClient) ValidateAPIKey(ctx context.Context) error {
+	c.factory.validations++
+	return c.Client.ValidateAPIKey(ctx)
+}
+
+func Test_apiKeyValidator(t *testing.T) {
+	ctx := context.Background()
+	factory := &countingFactory{Factory: mock.NewMockedHVClientFactory()}
+	var v apiKeyValidator
+
+	require.NoError(t, v.validate(ctx, factory, "valid-key"))
+	require.NoError(t, v.validate(ctx, factory, "valid-key"))
+	require.Equal(t, 1, factory.validations)
+
+	// rejected keys are cached as well, so that they are checked and forgotten only once
+	require.ErrorIs(t, v.validate(ctx, factory, mock.InvalidAPIKey), hvclient.ErrInvalidAPIKey)
+	require.ErrorIs(t, v.validate(ctx, factory, mock.InvalidAPIKey), hvclient.ErrInvalidAPIKey)
+	require.Equal(t, 2, factory.validations)
+	require.Equal(t, 1, factory.forgotten)
+
+	// the keys get validated again after the interval
+	for hash, result := range v.results {
+		result.checkedAt = time.Now().Add(-apiKeyValidationInterval)
+		v.results[hash] = result
+	}
+	require.NoError(t, v.validate(ctx, factory, "valid-key"))
+	require.ErrorIs(t, v.validate(ctx, factory, mock.InvalidAPIKey), hvclient.ErrInvalidAPIKey)
+	require.Equal(t, 4, factory.validations)
+	require.Equal(t, 2, factory.forgotten)
+}
+
+func Test_Reconcile_deleteWithRejectedAPIKey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, clusterv1.AddToScheme(scheme))
+	require.NoError(t, infrav1.AddToScheme(scheme))
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster", UID: "cluster-uid"}}
+	hvSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "hv-secret"},
+		Data:       map[string][]byte{"HIVELOCITY_API_KEY": []byte(mock.InvalidAPIKey)},
+	}
+	hvCluster := &infrav1.HivelocityCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "hv-cluster",
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			// the second finalizer keeps the object, so that the fake client does not delete it at once
+			Finalizers: []string{infrav1.ClusterFinalizer, "test.example.com/keep"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+			}},
+		},
+		Spec: infrav1.HivelocityClusterSpec{
+			HivelocitySecret: infrav1.HivelocitySecretRef{Name: hvSecret.Name, Key: "HIVELOCITY_API_KEY"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, hvSecret, hvCluster).
+		WithStatusSubresource(hvCluster).Build()
+	r := &HivelocityClusterReconciler{
+		Client:          c,
+		APIReader:       c,
+		HVClientFactory: mock.NewMockedHVClientFactory(),
+	}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(hvCluster)})
+	require.NoError(t, err)
+
+	// the finalizer of the controller is removed, although the API rejects the key
+	got := &infrav1.HivelocityCluster{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(hvCluster), got))
+	require.Equal(t, []string{"test.example.com/keep"}, got.Finalizers)
+	require.Equal(t, infrav1.HivelocityWrongAPIKeyReason, conditions.GetReason(got, infrav1.CredentialsAvailableCondition))
+}
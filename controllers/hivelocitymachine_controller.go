@@ -26,8 +26,12 @@ import (
 	secretutil "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/secrets"
 	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/device"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/tracing"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
@@ -50,6 +54,12 @@ type HivelocityMachineReconciler struct {
 	APIReader        client.Reader
 	HVClientFactory  hvclient.Factory
 	WatchFilterValue string
+
+	// DefaultAPIKey is used if the HivelocitySecret of a cluster does not exist and the cluster opted in with UseDefaultCredential.
+	DefaultAPIKey *secretutil.DefaultAPIKey
+
+	// TargetClusterClients provides the cached clients of the workload clusters, e.g. to read their Nodes.
+	TargetClusterClients *TargetClusterClients
 }
 
 //+kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
@@ -65,6 +75,12 @@ func (r *HivelocityMachineReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	logger.Info("Reconcile HivelocityMachine")
 
+	ctx, span := tracing.Tracer().Start(ctx, "HivelocityMachineReconciler.Reconcile", trace.WithAttributes(
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("caphv.hivelocitymachine", req.Name),
+	))
+	defer func() { tracing.End(span, reterr) }()
+
 	// Fetch the HivelocityMachine.
 	hivelocityMachine := &infrav1.HivelocityMachine{}
 	err := r.Get(ctx, req.NamespacedName, hivelocityMachine)
@@ -75,6 +91,11 @@ func (r *HivelocityMachineReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	span.SetAttributes(tracing.ProvisioningState(hivelocityMachine.Spec.Status.ProvisioningState))
+	if deviceID, err := hivelocityMachine.DeviceIDFromProviderID(); err == nil {
+		span.SetAttributes(tracing.DeviceID(deviceID))
+	}
+
 	logger = logger.WithValues("HivelocityMachine", klog.KObj(hivelocityMachine), "providerID", hivelocityMachine.Spec.ProviderID)
 
 	// Fetch the Machine.
@@ -120,7 +141,7 @@ func (r *HivelocityMachineReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	// Create the scope.
 	secretManager := secretutil.NewSecretManager(logger, r.Client, r.APIReader)
-	hvAPIKey, _, err := getAndValidateHivelocityAPIKey(ctx, req.Namespace, hvCluster, secretManager)
+	hvAPIKey, _, err := getAndValidateHivelocityAPIKey(ctx, req.Namespace, hvCluster, secretManager, r.DefaultAPIKey)
 	if err != nil {
 		conditions.MarkFalse(hvCluster, infrav1.CredentialsAvailableCondition, infrav1.HivelocityWrongAPIKeyReason, clusterv1.ConditionSeverityError, err.Error())
 		return hvAPIKeyErrorResult(ctx, err, hivelocityMachine, infrav1.DeviceReadyCondition, r.Client)
@@ -136,6 +157,7 @@ func (r *HivelocityMachineReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			HivelocityCluster: hvCluster,
 			HVClient:          hvClient,
 			APIReader:         r.APIReader,
+			WorkloadClient:    r.TargetClusterClients.Get(types.NamespacedName{Namespace: hvCluster.Namespace, Name: hvCluster.Name}),
 		},
 		Machine:           machine,
 		HivelocityMachine: hivelocityMachine,
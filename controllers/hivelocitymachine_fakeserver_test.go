@@ -0,0 +1,209 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/utils"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/test/helpers"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeServerDeviceID is the device which the machine gets from the fake server.
+const fakeServerDeviceID = 1000
+
+var _ = Describe("HivelocityMachineReconciler with the fake Hivelocity API", func() {
+	var (
+		capiCluster *clusterv1.Cluster
+		capiMachine *clusterv1.Machine
+
+		hvCluster *infrav1.HivelocityCluster
+		hvMachine *infrav1.HivelocityMachine
+
+		testNs *corev1.Namespace
+
+		hvSecret        *corev1.Secret
+		bootstrapSecret *corev1.Secret
+
+		machineKey client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		var err error
+		testNs, err = testEnv.CreateNamespace(ctx, "hivelocitymachine-fakeserver")
+		Expect(err).NotTo(HaveOccurred())
+
+		testEnv.FakeServer.AddDevice(hv.BareMetalDevice{
+			DeviceId:    fakeServerDeviceID,
+			ProductId:   500,
+			PowerStatus: "ON",
+			PrimaryIp:   "192.0.2.10",
+			Tags:        []string{"caphvlabel:deviceType=fakeServer", "caphv-use=allow"},
+		})
+		testEnv.FakeServer.SetIPAssignments(fakeServerDeviceID, hv.IpAssignment{
+			Version:   4,
+			Subnet:    "192.0.2.8/29",
+			UsableIps: []string{"192.0.2.10", "192.0.2.11"},
+			DeviceId:  fakeServerDeviceID,
+		})
+		testEnv.FakeServer.AddSSHKey(hv.SshKeyResponse{SshKeyId: 1, Name: "testsshkey"})
+		testEnv.FakeServer.SetImages(500, "Ubuntu 20.x")
+
+		capiCluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test1-",
+				Namespace:    testNs.Name,
+				Finalizers:   []string{clusterv1.ClusterFinalizer},
+			},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "HivelocityCluster",
+					Name:       "hv-fake",
+					Namespace:  testNs.Name,
+				},
+			},
+			Status: clusterv1.ClusterStatus{
+				InfrastructureReady: true,
+			},
+		}
+		Expect(testEnv.Create(ctx, capiCluster)).To(Succeed())
+
+		hvCluster = &infrav1.HivelocityCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "hv-fake",
+				Namespace: testNs.Name,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: "cluster.x-k8s.io/v1beta1",
+						Kind:       "Cluster",
+						Name:       capiCluster.Name,
+						UID:        capiCluster.UID,
+					},
+				},
+			},
+			Spec: getDefaultHivelocityClusterSpec(),
+		}
+
+		hvSecret = getDefaultHivelocitySecret(testNs.Name)
+		hvSecret.Data["HIVELOCITY_API_KEY"] = []byte(helpers.FakeServerAPIKey)
+		Expect(testEnv.Create(ctx, hvSecret)).To(Succeed())
+
+		bootstrapSecret = getDefaultBootstrapSecret(testNs.Name)
+		Expect(testEnv.Create(ctx, bootstrapSecret)).To(Succeed())
+
+		hivelocityMachineName := utils.GenerateName(nil, "hv-machine-")
+
+		capiMachine = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "capi-machine-",
+				Namespace:    testNs.Name,
+				Finalizers:   []string{clusterv1.MachineFinalizer},
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel: capiCluster.Name,
+				},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: capiCluster.Name,
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					Kind:       "HivelocityMachine",
+					Name:       hivelocityMachineName,
+				},
+				Bootstrap: clusterv1.Bootstrap{
+					DataSecretName: ptr.To("bootstrap-secret"),
+				},
+				FailureDomain: &defaultFailureDomain,
+			},
+		}
+		Expect(testEnv.Create(ctx, capiMachine)).To(Succeed())
+
+		hvMachine = &infrav1.HivelocityMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      hivelocityMachineName,
+				Namespace: testNs.Name,
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel: capiCluster.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Machine",
+						Name:       capiMachine.Name,
+						UID:        capiMachine.UID,
+					},
+				},
+			},
+			Spec: infrav1.HivelocityMachineSpec{
+				ImageName: "Ubuntu 20.x",
+				DeviceSelector: infrav1.DeviceSelector{
+					MatchLabels: map[string]string{
+						"deviceType": "fakeServer",
+					},
+				},
+			},
+		}
+		Expect(testEnv.Create(ctx, hvMachine)).To(Succeed())
+		Expect(testEnv.Create(ctx, hvCluster)).To(Succeed())
+
+		machineKey = client.ObjectKey{Namespace: testNs.Name, Name: hvMachine.Name}
+	})
+
+	AfterEach(func() {
+		Expect(testEnv.Cleanup(ctx, testNs, capiCluster, hvCluster, capiMachine,
+			hvMachine, hvSecret, bootstrapSecret)).To(Succeed())
+	})
+
+	It("provisions the device through the API", func() {
+		Eventually(func() bool {
+			if err := testEnv.Get(ctx, machineKey, hvMachine); err != nil {
+				return false
+			}
+			if hvMachine.Spec.ProviderID == nil || !hvMachine.Status.Ready {
+				return false
+			}
+			return hvMachine.Spec.Status.ProvisioningState == infrav1.StateDeviceProvisioned
+		}, timeout, time.Second).Should(BeTrue())
+
+		Expect(*hvMachine.Spec.ProviderID).To(Equal(fmt.Sprintf("hivelocity://%d", fakeServerDeviceID)))
+		Expect(hvMachine.Status.Addresses).To(ContainElement(clusterv1.MachineAddress{
+			Type:    clusterv1.MachineInternalIP,
+			Address: "192.0.2.11",
+		}))
+
+		device, ok := testEnv.FakeServer.Device(fakeServerDeviceID)
+		Expect(ok).To(BeTrue())
+		Expect(device.PowerStatus).To(Equal("ON"))
+		Expect(device.OsName).To(Equal("Ubuntu 20.x"))
+		Expect(device.Script).To(Equal("#cloud-config\nmy-bootstrap"))
+		Expect(device.Tags).To(ContainElements(
+			"caphv-cluster-name=hv-fake",
+			fmt.Sprintf("caphv-machine-name=%s", hvMachine.Name),
+		))
+		Expect(testEnv.FakeServer.Events(fakeServerDeviceID)).NotTo(BeEmpty())
+	})
+})
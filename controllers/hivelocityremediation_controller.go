@@ -18,45 +18,154 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	secretutil "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/secrets"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/remediation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 )
 
 // HivelocityRemediationReconciler reconciles a HivelocityRemediation object.
 type HivelocityRemediationReconciler struct {
 	client.Client
+	APIReader        client.Reader
+	HVClientFactory  hvclient.Factory
 	Scheme           *runtime.Scheme
 	WatchFilterValue string
+
+	// DefaultAPIKey is used if the HivelocitySecret of a cluster does not exist and the cluster opted in with UseDefaultCredential.
+	DefaultAPIKey *secretutil.DefaultAPIKey
 }
 
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=hivelocityremediations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=hivelocityremediations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=hivelocityremediations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=hivelocitymachines,verbs=get;list;watch;update;patch
+
+// Reconcile remediates the unhealthy machine which is referenced by the HivelocityRemediation.
+// The HivelocityRemediation gets created by a MachineHealthCheck and has the same name as the Machine.
+func (r *HivelocityRemediationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	// Fetch the HivelocityRemediation.
+	hvRemediation := &infrav1.HivelocityRemediation{}
+	if err := r.Get(ctx, req.NamespacedName, hvRemediation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	logger = logger.WithValues("HivelocityRemediation", klog.KObj(hvRemediation))
+
+	// Fetch the Machine.
+	machine, err := util.GetOwnerMachine(ctx, r.Client, hvRemediation.ObjectMeta)
+	if err != nil {
+		logger.Error(err, "GetOwnerMachine failed")
+		return ctrl.Result{}, err
+	}
+	if machine == nil {
+		logger.Info("Machine Controller has not yet set OwnerRef")
+		return ctrl.Result{}, nil
+	}
+
+	logger = logger.WithValues("Machine", klog.KObj(machine))
+
+	// Fetch the Cluster.
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machine.ObjectMeta)
+	if err != nil {
+		logger.Info("Machine is missing cluster label or cluster does not exist", "error", err)
+		return ctrl.Result{}, nil
+	}
+
+	if annotations.IsPaused(cluster, hvRemediation) {
+		logger.Info("HivelocityRemediation or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	logger = logger.WithValues("Cluster", klog.KObj(cluster))
+
+	// Fetch the HivelocityMachine.
+	hvMachine := &infrav1.HivelocityMachine{}
+	hvMachineName := client.ObjectKey{
+		Namespace: machine.Namespace,
+		Name:      machine.Spec.InfrastructureRef.Name,
+	}
+	if err := r.Client.Get(ctx, hvMachineName, hvMachine); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("HivelocityMachine not found", "error", err)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get HivelocityMachine: %w", err)
+	}
+
+	logger = logger.WithValues("HivelocityMachine", klog.KObj(hvMachine))
+
+	// Fetch the HivelocityCluster.
+	hvCluster := &infrav1.HivelocityCluster{}
+	hvClusterName := client.ObjectKey{
+		Namespace: machine.Namespace,
+		Name:      cluster.Spec.InfrastructureRef.Name,
+	}
+	if err := r.Client.Get(ctx, hvClusterName, hvCluster); err != nil {
+		logger.Info("HivelocityCluster is not available yet", "error", err)
+		return ctrl.Result{}, nil
+	}
+
+	logger = logger.WithValues("HivelocityCluster", klog.KObj(hvCluster))
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	// Create the scope.
+	secretManager := secretutil.NewSecretManager(logger, r.Client, r.APIReader)
+	hvAPIKey, _, err := getAndValidateHivelocityAPIKey(ctx, req.Namespace, hvCluster, secretManager, r.DefaultAPIKey)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get Hivelocity API key: %w", err)
+	}
+
+	remediationScope, err := scope.NewRemediationScope(scope.RemediationScopeParams{
+		Client:                r.Client,
+		Logger:                logger,
+		HVClient:              r.HVClientFactory.NewClient(hvAPIKey),
+		Machine:               machine,
+		HivelocityMachine:     hvMachine,
+		HivelocityRemediation: hvRemediation,
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
+	}
+
+	// Always close the scope when exiting this function so we can persist any HivelocityRemediation changes.
+	defer func() {
+		if err := remediationScope.Close(ctx); err != nil && reterr == nil {
+			reterr = err
+		}
+	}()
+
+	if !hvRemediation.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the HivelocityRemediation object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.1/pkg/reconcile
-func (r *HivelocityRemediationReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
-
-	// TODO(user): your logic here
-
-	return ctrl.Result{}, nil
+	return remediation.NewService(remediationScope).Reconcile(ctx)
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *HivelocityRemediationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *HivelocityRemediationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	log := ctrl.LoggerFrom(ctx)
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
 		For(&infrav1.HivelocityRemediation{}).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
 		Complete(r)
 }
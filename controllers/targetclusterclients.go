@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TargetClusterClients holds the cached clients of the running target cluster managers, keyed by the
+// HivelocityCluster. Other controllers use them to read objects of a workload cluster without creating a new
+// client on each reconcile. All methods may be called on a nil TargetClusterClients.
+type TargetClusterClients struct {
+	mu      sync.RWMutex
+	clients map[types.NamespacedName]client.Reader
+}
+
+// Get returns the client of the workload cluster of the HivelocityCluster, or nil if its target cluster manager
+// is not running.
+func (c *TargetClusterClients) Get(key types.NamespacedName) client.Reader {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clients[key]
+}
+
+func (c *TargetClusterClients) set(key types.NamespacedName, reader client.Reader) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clients == nil {
+		c.clients = make(map[types.NamespacedName]client.Reader)
+	}
+	c.clients[key] = reader
+}
+
+func (c *TargetClusterClients) delete(key types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.clients, key)
+}
@@ -18,19 +18,25 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/controllers"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
+	secretutil "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/secrets"
 	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/tracing"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/utils"
 	caphvversion "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/version"
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.) to ensure that exec-entrypoint and run can make use of them.
@@ -39,6 +45,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -69,6 +76,11 @@ var (
 	hivelocityMachineConcurrency int
 	logLevel                     string
 	syncPeriod                   time.Duration
+	supportTickets               bool
+	tracingOptions               tracing.Options
+	apiKeyFile                   string
+	defaultSecret                string
+	defaultSecretKey             string
 )
 
 func main() {
@@ -84,6 +96,13 @@ func main() {
 	fs.StringVar(&watchNamespace, "namespace", "", "Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
 	fs.StringVar(&logLevel, "log-level", "debug", "Specifies log level. Options are 'debug', 'info' and 'error'")
 	fs.DurationVar(&syncPeriod, "sync-period", 3*time.Minute, "The minimum interval at which watched resources are reconciled (e.g. 3m)")
+	fs.BoolVar(&supportTickets, "support-tickets", false, "Open Hivelocity support tickets for quarantined devices, and clear their permanent error once the ticket is resolved.")
+	fs.StringVar(&tracingOptions.Endpoint, "tracing-endpoint", "", "Host and port of the OTLP gRPC receiver for traces (e.g. otel-collector:4317). If unspecified, tracing is disabled.")
+	fs.BoolVar(&tracingOptions.Insecure, "tracing-insecure", false, "Connect to the OTLP receiver without TLS.")
+	fs.Float64Var(&tracingOptions.SampleRatio, "tracing-sample-ratio", 1, "Ratio of reconciles which are traced, between 0 and 1.")
+	fs.StringVar(&apiKeyFile, "hivelocity-api-key-file", "", "File with the default Hivelocity API key, e.g. rendered by the Vault agent. It is used for HivelocityClusters with spec.hivelocitySecret.useDefaultCredential whose secret does not exist. The file is watched and its API key is cached until the file changes.")
+	fs.StringVar(&defaultSecret, "default-hivelocity-secret", "", "Secret (namespace/name) with the default Hivelocity API key. It is used for HivelocityClusters with spec.hivelocitySecret.useDefaultCredential whose secret does not exist. Mutually exclusive with --hivelocity-api-key-file.")
+	fs.StringVar(&defaultSecretKey, "default-hivelocity-secret-key", "HIVELOCITY_API_KEY", "Key of the API key in the secret of --default-hivelocity-secret.")
 
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 
@@ -123,28 +142,63 @@ func main() {
 	// Initialize event recorder.
 	record.InitFromRecorder(mgr.GetEventRecorderFor("hv-controller"))
 
+	if err := metrics.RegisterMachineStateCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
+	shutdownTracing, err := tracing.Setup(ctx, tracingOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 
+	// all controllers share the factory, so that clients with the same API key share the device inventory.
+	hvClientFactory := &hvclient.HivelocityFactory{}
+
+	// the HivelocityCluster controller registers the cached clients of the workload clusters for the other controllers.
+	targetClusterClients := &controllers.TargetClusterClients{}
+
+	defaultAPIKey, err := newDefaultAPIKey(mgr.GetAPIReader())
+	if err != nil {
+		setupLog.Error(err, "invalid default Hivelocity credential")
+		os.Exit(1)
+	}
+	if defaultAPIKey.IsSet() && defaultAPIKey.File != "" {
+		// cache the API key of the file and reload it when the file changes.
+		if err := mgr.Add(defaultAPIKey); err != nil {
+			setupLog.Error(err, "unable to watch the Hivelocity API key file")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.HivelocityClusterReconciler{
 		Client:                         mgr.GetClient(),
 		APIReader:                      mgr.GetAPIReader(),
-		HVClientFactory:                &hvclient.HivelocityFactory{},
+		HVClientFactory:                hvClientFactory,
 		Scheme:                         mgr.GetScheme(),
 		WatchFilterValue:               watchFilterValue,
 		TargetClusterManagersWaitGroup: &wg,
+		SupportTickets:                 supportTickets,
+		DefaultAPIKey:                  defaultAPIKey,
+		TargetClusterClients:           targetClusterClients,
 	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: hivelocityClusterConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HivelocityCluster")
 		os.Exit(1)
 	}
 	if err = (&controllers.HivelocityMachineReconciler{
-		Client:           mgr.GetClient(),
-		APIReader:        mgr.GetAPIReader(),
-		HVClientFactory:  &hvclient.HivelocityFactory{},
-		WatchFilterValue: watchFilterValue,
+		Client:               mgr.GetClient(),
+		APIReader:            mgr.GetAPIReader(),
+		HVClientFactory:      hvClientFactory,
+		WatchFilterValue:     watchFilterValue,
+		DefaultAPIKey:        defaultAPIKey,
+		TargetClusterClients: targetClusterClients,
 	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: hivelocityMachineConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HivelocityMachine")
 		os.Exit(1)
@@ -158,9 +212,12 @@ func main() {
 	}
 	if err = (&controllers.HivelocityRemediationReconciler{
 		Client:           mgr.GetClient(),
+		APIReader:        mgr.GetAPIReader(),
+		HVClientFactory:  hvClientFactory,
 		Scheme:           mgr.GetScheme(),
 		WatchFilterValue: watchFilterValue,
-	}).SetupWithManager(mgr); err != nil {
+		DefaultAPIKey:    defaultAPIKey,
+	}).SetupWithManager(ctx, mgr, controller.Options{}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HivelocityRemediation")
 		os.Exit(1)
 	}
@@ -212,4 +269,31 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	// the context of the manager is done, so the remaining spans get flushed with a new one.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "unable to flush traces")
+	}
+}
+
+// newDefaultAPIKey returns the default credential of the flags. It is nil if none is configured.
+func newDefaultAPIKey(reader client.Reader) (*secretutil.DefaultAPIKey, error) {
+	if apiKeyFile == "" && defaultSecret == "" {
+		return nil, nil
+	}
+	if apiKeyFile != "" && defaultSecret != "" {
+		return nil, fmt.Errorf("--hivelocity-api-key-file and --default-hivelocity-secret are mutually exclusive")
+	}
+
+	var secret types.NamespacedName
+	if defaultSecret != "" {
+		namespace, name, ok := strings.Cut(defaultSecret, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("--default-hivelocity-secret %q is not of the form namespace/name", defaultSecret)
+		}
+		secret = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+	return secretutil.NewDefaultAPIKey(apiKeyFile, secret, defaultSecretKey, reader), nil
 }
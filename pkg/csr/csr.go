@@ -23,6 +23,7 @@ import (
 	"encoding/asn1"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"strings"
 
@@ -76,7 +77,12 @@ func ValidateKubeletCSR(csr *x509.CertificateRequest, machineName string, addres
 	for _, address := range addresses {
 		switch address.Type {
 		case clusterv1.MachineInternalIP, clusterv1.MachineExternalIP:
-			allowedIPAddresses[strings.Split(address.Address, "/")[0]] = struct{}{}
+			// normalize the address, since IPv6 addresses have several textual representations
+			ip := net.ParseIP(strings.Split(address.Address, "/")[0])
+			if ip == nil {
+				continue
+			}
+			allowedIPAddresses[ip.String()] = struct{}{}
 		}
 	}
 
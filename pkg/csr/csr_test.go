@@ -19,6 +19,7 @@ package csr_test
 import (
 	"crypto/x509"
 	"encoding/pem"
+	"net"
 
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/csr"
 	. "github.com/onsi/ginkgo/v2"
@@ -56,4 +57,18 @@ XwIhAMOhY6EyOQX3j356MVa0b2ixNKy9EDtMZbAWVXrClnRl
 	It("should not fail", func() {
 		Expect(csr.ValidateKubeletCSR(cr, name, addresses)).To(Succeed())
 	})
+
+	It("should accept IPv6 addresses in non-canonical form", func() {
+		cr.IPAddresses = append(cr.IPAddresses, net.ParseIP("2001:db8::2"))
+		addresses = append(addresses, clusterv1.MachineAddress{
+			Type:    clusterv1.MachineExternalIP,
+			Address: "2001:0db8:0000::0002",
+		})
+		Expect(csr.ValidateKubeletCSR(cr, name, addresses)).To(Succeed())
+	})
+
+	It("should fail for unknown IPv6 addresses", func() {
+		cr.IPAddresses = append(cr.IPAddresses, net.ParseIP("2001:db8::3"))
+		Expect(csr.ValidateKubeletCSR(cr, name, addresses)).ToNot(Succeed())
+	})
 })
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// machinesCollectTimeout is the maximum time to list the HivelocityMachines during a scrape.
+const machinesCollectTimeout = 10 * time.Second
+
+var machinesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "machines"),
+	"Number of HivelocityMachines by provisioning state.",
+	[]string{"state"},
+	nil,
+)
+
+// machineStateCollector counts the HivelocityMachines per provisioning state on each scrape.
+type machineStateCollector struct {
+	reader client.Reader
+}
+
+// RegisterMachineStateCollector registers the collector for the number of HivelocityMachines per provisioning state.
+// The reader should be backed by the cache of the manager, so that a scrape does not query the API server.
+func RegisterMachineStateCollector(reader client.Reader) error {
+	return metrics.Registry.Register(&machineStateCollector{reader: reader})
+}
+
+// Describe implements prometheus.Collector.
+func (c *machineStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- machinesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *machineStateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), machinesCollectTimeout)
+	defer cancel()
+
+	var machines infrav1.HivelocityMachineList
+	if err := c.reader.List(ctx, &machines); err != nil {
+		ctrl.Log.WithName("metrics").Error(err, "failed to list HivelocityMachines")
+		return
+	}
+
+	for state, count := range countMachinesByState(machines.Items) {
+		ch <- prometheus.MustNewConstMetric(machinesDesc, prometheus.GaugeValue, float64(count), string(state))
+	}
+}
+
+// countMachinesByState returns the number of machines per provisioning state.
+// Machines which have not started provisioning yet are counted as "none".
+func countMachinesByState(machines []infrav1.HivelocityMachine) map[infrav1.ProvisioningState]int {
+	counts := make(map[infrav1.ProvisioningState]int)
+	for i := range machines {
+		state := machines[i].Spec.Status.ProvisioningState
+		if state == infrav1.StateNone {
+			state = "none"
+		}
+		counts[state]++
+	}
+	return counts
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_countMachinesByState(t *testing.T) {
+	machine := func(state infrav1.ProvisioningState) infrav1.HivelocityMachine {
+		var m infrav1.HivelocityMachine
+		m.Spec.Status.ProvisioningState = state
+		return m
+	}
+
+	counts := countMachinesByState([]infrav1.HivelocityMachine{
+		machine(infrav1.StateNone),
+		machine(infrav1.StateProvisionDevice),
+		machine(infrav1.StateDeviceProvisioned),
+		machine(infrav1.StateDeviceProvisioned),
+	})
+	require.Equal(t, map[infrav1.ProvisioningState]int{
+		"none":                         1,
+		infrav1.StateProvisionDevice:   1,
+		infrav1.StateDeviceProvisioned: 2,
+	}, counts)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the controller.
+// They are registered in the registry of controller-runtime and served on its metrics endpoint.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "caphv"
+
+// DevicesQuarantined counts the devices which got the permanent error tag, so that they are not used again.
+// The IDs of the devices are only reported in the events, because a label per device would never go away.
+var DevicesQuarantined = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "devices_quarantined_total",
+		Help:      "Number of devices which were marked with the permanent error tag.",
+	},
+	[]string{"reason"},
+)
+
+// DeviceInventoryLastRefresh is the time the device inventory of an API key was listed the last time.
+// The staleness of the inventory is time() minus this value.
+var DeviceInventoryLastRefresh = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "device_inventory_last_refresh_timestamp_seconds",
+		Help:      "Unix time of the last refresh of the cached device inventory.",
+	},
+	[]string{"inventory"},
+)
+
+// DeviceInventoryDevices is the number of devices in the device inventory of an API key.
+var DeviceInventoryDevices = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "device_inventory_devices",
+		Help:      "Number of devices in the cached device inventory.",
+	},
+	[]string{"inventory"},
+)
+
+// HivelocityAPIRequests counts the requests to the Hivelocity API by operation and status code.
+// Requests which failed without a response have the code "error".
+var HivelocityAPIRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hivelocity_api_requests_total",
+		Help:      "Number of requests to the Hivelocity API by operation and status code.",
+	},
+	[]string{"operation", "code"},
+)
+
+// HivelocityAPIRequestDuration is the latency of requests to the Hivelocity API.
+// The time spent waiting for the client-side rate limiter is not included.
+var HivelocityAPIRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "hivelocity_api_request_duration_seconds",
+		Help:      "Latency of requests to the Hivelocity API by operation.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	},
+	[]string{"operation"},
+)
+
+// HivelocityAPIRateLimited counts the responses of the Hivelocity API with status 429.
+var HivelocityAPIRateLimited = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hivelocity_api_rate_limited_total",
+		Help:      "Number of requests to the Hivelocity API which were answered with 429 Too Many Requests.",
+	},
+	[]string{"operation"},
+)
+
+// HivelocityAPIRequestsInFlight is the number of requests to the Hivelocity API which wait for a response.
+var HivelocityAPIRequestsInFlight = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "hivelocity_api_requests_in_flight",
+		Help:      "Number of requests to the Hivelocity API which wait for a response.",
+	},
+)
+
+// ProvisioningStateDuration is the time a machine spent in a provisioning state before it changed to another state.
+var ProvisioningStateDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provisioning_state_duration_seconds",
+		Help:      "Time a machine spent in a provisioning state.",
+		Buckets:   []float64{1, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	},
+	[]string{"state"},
+)
+
+// ProvisioningDuration is the time from associating a device until the machine is provisioned.
+// Attempts with other devices, e.g. after a device reloaded too long, are included.
+var ProvisioningDuration = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provisioning_duration_seconds",
+		Help:      "Time from associating a device until the machine is provisioned.",
+		Buckets:   []float64{60, 300, 600, 900, 1200, 1800, 2700, 3600, 5400, 7200},
+	},
+)
+
+// ProvisioningGoBacks counts the transitions of the state machine back to a previous state.
+var ProvisioningGoBacks = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provisioning_go_back_total",
+		Help:      "Number of transitions back to a previous provisioning state by state and reason.",
+	},
+	[]string{"state", "reason"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		DevicesQuarantined,
+		DeviceInventoryLastRefresh,
+		DeviceInventoryDevices,
+		HivelocityAPIRequests,
+		HivelocityAPIRequestDuration,
+		HivelocityAPIRateLimited,
+		HivelocityAPIRequestsInFlight,
+		ProvisioningStateDuration,
+		ProvisioningDuration,
+		ProvisioningGoBacks,
+	)
+}
+
+// RecordDeviceQuarantined increments the counter of quarantined devices.
+func RecordDeviceQuarantined(reason string) {
+	DevicesQuarantined.WithLabelValues(reason).Inc()
+}
+
+// RecordDeviceInventoryRefresh records a refresh of the device inventory.
+func RecordDeviceInventoryRefresh(inventory string, t time.Time, devices int) {
+	DeviceInventoryLastRefresh.WithLabelValues(inventory).Set(float64(t.Unix()))
+	DeviceInventoryDevices.WithLabelValues(inventory).Set(float64(devices))
+}
+
+// RecordHivelocityAPIRequest records a finished request to the Hivelocity API.
+// The status code is zero if the request failed without a response.
+func RecordHivelocityAPIRequest(operation string, statusCode int, d time.Duration) {
+	code := "error"
+	if statusCode != 0 {
+		code = strconv.Itoa(statusCode)
+	}
+	HivelocityAPIRequests.WithLabelValues(operation, code).Inc()
+	HivelocityAPIRequestDuration.WithLabelValues(operation).Observe(d.Seconds())
+	if statusCode == http.StatusTooManyRequests {
+		HivelocityAPIRateLimited.WithLabelValues(operation).Inc()
+	}
+}
+
+// RecordProvisioningStateChange records the time a machine spent in a provisioning state.
+func RecordProvisioningStateChange(state string, d time.Duration) {
+	ProvisioningStateDuration.WithLabelValues(state).Observe(d.Seconds())
+}
+
+// RecordProvisioned records the time from associating a device until the machine was provisioned.
+func RecordProvisioned(d time.Duration) {
+	ProvisioningDuration.Observe(d.Seconds())
+}
+
+// RecordProvisioningGoBack increments the counter of transitions back to a previous provisioning state.
+func RecordProvisioningGoBack(state, reason string) {
+	ProvisioningGoBacks.WithLabelValues(state, reason).Inc()
+}
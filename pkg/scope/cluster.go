@@ -42,6 +42,7 @@ type ClusterScopeParams struct {
 	HVClient          hvclient.Client
 	Cluster           *clusterv1.Cluster
 	HivelocityCluster *infrav1.HivelocityCluster
+	WorkloadClient    client.Reader
 }
 
 // NewClusterScope creates a new Scope from the supplied parameters.
@@ -69,6 +70,7 @@ func NewClusterScope(_ context.Context, params ClusterScopeParams) (*ClusterScop
 		Cluster:           params.Cluster,
 		HivelocityCluster: params.HivelocityCluster,
 		HVClient:          params.HVClient,
+		WorkloadClient:    params.WorkloadClient,
 		patchHelper:       helper,
 	}, nil
 }
@@ -83,6 +85,10 @@ type ClusterScope struct {
 
 	Cluster           *clusterv1.Cluster
 	HivelocityCluster *infrav1.HivelocityCluster
+
+	// WorkloadClient is the cached client of the workload cluster. It is nil as long as the
+	// target cluster manager of the HivelocityCluster is not running.
+	WorkloadClient client.Reader
 }
 
 // Name returns the HivelocityCluster name.
@@ -95,6 +95,15 @@ func (m *MachineScope) Name() string {
 	return m.HivelocityMachine.Name
 }
 
+// Hostname returns the FQDN of the device. The Hivelocity API requires a FQDN.
+// If the cluster manages DNS records, the hostname is part of the DNS zone.
+func (m *MachineScope) Hostname() string {
+	if dns := m.HivelocityCluster.Spec.DNS; dns != nil {
+		return dns.FQDN(m.Name())
+	}
+	return fmt.Sprintf("%s.example.com", m.Name())
+}
+
 // Namespace returns the namespace name.
 func (m *MachineScope) Namespace() string {
 	return m.HivelocityMachine.Namespace
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RemediationScopeParams defines the input parameters used to create a new remediation scope.
+type RemediationScopeParams struct {
+	Client                client.Client
+	Logger                logr.Logger
+	HVClient              hvclient.Client
+	Machine               *clusterv1.Machine
+	HivelocityMachine     *infrav1.HivelocityMachine
+	HivelocityRemediation *infrav1.HivelocityRemediation
+}
+
+// NewRemediationScope creates a new remediation scope from the supplied parameters.
+// This is meant to be called for each reconcile iteration.
+func NewRemediationScope(params RemediationScopeParams) (*RemediationScope, error) {
+	if params.Machine == nil {
+		return nil, errors.New("failed to generate new scope from nil Machine")
+	}
+	if params.HivelocityMachine == nil {
+		return nil, errors.New("failed to generate new scope from nil HivelocityMachine")
+	}
+	if params.HivelocityRemediation == nil {
+		return nil, errors.New("failed to generate new scope from nil HivelocityRemediation")
+	}
+	if params.HVClient == nil {
+		return nil, errors.New("failed to generate new scope from nil HVClient")
+	}
+
+	helper, err := patch.NewHelper(params.HivelocityRemediation, params.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init patch helper: %w", err)
+	}
+
+	machineHelper, err := patch.NewHelper(params.HivelocityMachine, params.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init patch helper of HivelocityMachine: %w", err)
+	}
+
+	return &RemediationScope{
+		Logger:                params.Logger,
+		Client:                params.Client,
+		HVClient:              params.HVClient,
+		Machine:               params.Machine,
+		HivelocityMachine:     params.HivelocityMachine,
+		HivelocityRemediation: params.HivelocityRemediation,
+		patchHelper:           helper,
+		machinePatchHelper:    machineHelper,
+	}, nil
+}
+
+// RemediationScope defines the basic context for the remediation of a machine.
+type RemediationScope struct {
+	logr.Logger
+	Client             client.Client
+	patchHelper        *patch.Helper
+	machinePatchHelper *patch.Helper
+
+	HVClient              hvclient.Client
+	Machine               *clusterv1.Machine
+	HivelocityMachine     *infrav1.HivelocityMachine
+	HivelocityRemediation *infrav1.HivelocityRemediation
+}
+
+// Close closes the current scope persisting the remediation status and the changes of the HivelocityMachine.
+func (m *RemediationScope) Close(ctx context.Context) error {
+	if err := m.machinePatchHelper.Patch(ctx, m.HivelocityMachine); err != nil {
+		return fmt.Errorf("failed to patch HivelocityMachine: %w", err)
+	}
+	return m.patchHelper.Patch(ctx, m.HivelocityRemediation)
+}
+
+// PatchObject persists the remediation spec and status.
+func (m *RemediationScope) PatchObject(ctx context.Context) error {
+	return m.patchHelper.Patch(ctx, m.HivelocityRemediation)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultAPIKey is the credential of the manager. It is used for HivelocityClusters whose HivelocitySecret
+// does not exist, so that not every namespace needs a copy of the API key.
+type DefaultAPIKey struct {
+	// File is the path of a file with the API key, e.g. rendered by the Vault agent or mounted by the
+	// Secrets Store CSI driver. While Start watches it, the API key is cached and reloaded when the file changes,
+	// so that rotated keys get picked up without a restart. Otherwise it is read on every use.
+	File string
+
+	// Secret is a secret with the API key, usually in the namespace of the manager. It is only used if File is empty.
+	Secret types.NamespacedName
+
+	// Key is the key of the API key in Secret.
+	Key string
+
+	reader client.Reader
+
+	mu       sync.RWMutex
+	watching bool
+	apiKey   string
+	err      error
+}
+
+// NewDefaultAPIKey returns the default credential of the manager. The reader is used to get the secret.
+func NewDefaultAPIKey(file string, secret types.NamespacedName, key string, reader client.Reader) *DefaultAPIKey {
+	return &DefaultAPIKey{
+		File:   file,
+		Secret: secret,
+		Key:    key,
+		reader: reader,
+	}
+}
+
+// IsSet returns true if a default credential is configured.
+func (d *DefaultAPIKey) IsSet() bool {
+	return d != nil && (d.File != "" || d.Secret.Name != "")
+}
+
+// APIKey returns the API key of the file or the secret.
+func (d *DefaultAPIKey) APIKey(ctx context.Context) (string, error) {
+	if d.File != "" {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		if d.watching {
+			return d.apiKey, d.err
+		}
+		return d.readFile()
+	}
+
+	var secret corev1.Secret
+	if err := d.reader.Get(ctx, d.Secret, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", &ResolveSecretRefError{
+				Message: fmt.Sprintf("The default Hivelocity secret %s does not exist", d.Secret),
+			}
+		}
+		return "", fmt.Errorf("failed to get default Hivelocity secret: %w", err)
+	}
+	return validAPIKey(string(secret.Data[d.Key]))
+}
+
+// Start watches File and caches its API key until ctx is done. It implements manager.Runnable.
+// The directory of the file is watched, because the file usually gets replaced instead of written,
+// e.g. by the kubelet when it updates a mounted secret. Without File, Start only waits for ctx.
+func (d *DefaultAPIKey) Start(ctx context.Context) error {
+	if d.File == "" {
+		<-ctx.Done()
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher for Hivelocity API key file: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(d.File)); err != nil {
+		return fmt.Errorf("failed to watch Hivelocity API key file: %w", err)
+	}
+
+	d.reload()
+	defer func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.watching = false
+	}()
+
+	logger := log.FromContext(ctx).WithName("default-api-key")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			d.reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Error(err, "Failed to watch Hivelocity API key file", "file", d.File)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The API key is cached on all replicas,
+// so that it is ready when a replica becomes the leader.
+func (d *DefaultAPIKey) NeedLeaderElection() bool {
+	return false
+}
+
+// reload reads the file into the cache, which is used while watching.
+func (d *DefaultAPIKey) reload() {
+	apiKey, err := d.readFile()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.watching = true
+	d.apiKey, d.err = apiKey, err
+}
+
+func (d *DefaultAPIKey) readFile() (string, error) {
+	data, err := os.ReadFile(d.File)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Hivelocity API key file: %w", err)
+	}
+	return validAPIKey(strings.TrimSpace(string(data)))
+}
+
+func validAPIKey(apiKey string) (string, error) {
+	if apiKey == "" {
+		return "", &HivelocityAPIKeyValidationError{}
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDefaultAPIKey_file(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "api-key")
+	require.NoError(t, os.WriteFile(file, []byte("old-key\n"), 0o600))
+	d := NewDefaultAPIKey(file, types.NamespacedName{}, "", nil)
+	require.True(t, d.IsSet())
+
+	apiKey, err := d.APIKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "old-key", apiKey)
+
+	// A rotated key gets picked up without a restart.
+	require.NoError(t, os.WriteFile(file, []byte("new-key"), 0o600))
+	apiKey, err = d.APIKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "new-key", apiKey)
+
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = d.APIKey(context.Background())
+	require.ErrorAs(t, err, new(*HivelocityAPIKeyValidationError))
+}
+
+func TestDefaultAPIKey_watch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "api-key")
+	require.NoError(t, os.WriteFile(file, []byte("old-key\n"), 0o600))
+	d := NewDefaultAPIKey(file, types.NamespacedName{}, "", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Start(ctx) }()
+
+	hasAPIKey := func(want string) func() bool {
+		return func() bool {
+			apiKey, err := d.APIKey(context.Background())
+			return err == nil && apiKey == want
+		}
+	}
+	require.Eventually(t, func() bool {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return d.watching
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, hasAPIKey("old-key")())
+
+	// The file gets replaced, like the kubelet does it with mounted secrets.
+	newFile := filepath.Join(dir, "api-key.new")
+	require.NoError(t, os.WriteFile(newFile, []byte("new-key"), 0o600))
+	require.NoError(t, os.Rename(newFile, file))
+	require.Eventually(t, hasAPIKey("new-key"), 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// Without watcher, the file is read on every use.
+	require.NoError(t, os.WriteFile(file, []byte("newer-key"), 0o600))
+	require.True(t, hasAPIKey("newer-key")())
+}
+
+func TestDefaultAPIKey_secret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "caphv-system", Name: "hivelocity"},
+		Data:       map[string][]byte{"HIVELOCITY_API_KEY": []byte("default-key")},
+	}
+	reader := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	d := NewDefaultAPIKey("", types.NamespacedName{Namespace: "caphv-system", Name: "hivelocity"}, "HIVELOCITY_API_KEY", reader)
+	require.True(t, d.IsSet())
+	apiKey, err := d.APIKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "default-key", apiKey)
+
+	d = NewDefaultAPIKey("", types.NamespacedName{Namespace: "caphv-system", Name: "missing"}, "HIVELOCITY_API_KEY", reader)
+	_, err = d.APIKey(context.Background())
+	require.ErrorAs(t, err, new(*ResolveSecretRefError))
+}
+
+func TestDefaultAPIKey_notSet(t *testing.T) {
+	var d *DefaultAPIKey
+	require.False(t, d.IsSet())
+	require.False(t, NewDefaultAPIKey("", types.NamespacedName{}, "HIVELOCITY_API_KEY", nil).IsSet())
+}
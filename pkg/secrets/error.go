@@ -18,6 +18,7 @@ package secretutil
 
 import (
 	"fmt"
+	"strings"
 )
 
 // ResolveSecretRefError is returned when the  secret
@@ -37,3 +38,17 @@ type HivelocityAPIKeyValidationError struct{}
 func (e HivelocityAPIKeyValidationError) Error() string {
 	return "Hivelocity API key is invalid"
 }
+
+// HivelocityAPIKeyRejectedError is returned when the Hivelocity API rejects all API keys of the secret.
+type HivelocityAPIKeyRejectedError struct {
+	// SecretKeys are the keys in the secret of the rejected API keys. It is empty if the API rejected
+	// the default credential of the manager.
+	SecretKeys []string
+}
+
+func (e HivelocityAPIKeyRejectedError) Error() string {
+	if len(e.SecretKeys) == 0 {
+		return "Hivelocity API rejected the default API key of the manager"
+	}
+	return fmt.Sprintf("Hivelocity API rejected the API key of %s", strings.Join(e.SecretKeys, " and "))
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// APIErrorCode is the structured reason of an error response of the Hivelocity API.
+// The API has no machine-readable error codes, so the code is derived from the status code of the response and
+// the status in the "code" field of the body. Only the power state of a device is told apart by the message.
+type APIErrorCode string
+
+const (
+	// APIErrorCodeDevicePoweredOff is returned if an action needs a device which is powered on.
+	APIErrorCodeDevicePoweredOff APIErrorCode = "device-powered-off"
+
+	// APIErrorCodeDevicePoweredOn is returned if an action needs a device which is powered off.
+	APIErrorCodeDevicePoweredOn APIErrorCode = "device-powered-on"
+
+	// APIErrorCodeDeviceBusy is returned if another task runs on the device, for example a reload.
+	APIErrorCodeDeviceBusy APIErrorCode = "device-busy"
+
+	// APIErrorCodeNotFound is returned if the resource does not exist.
+	APIErrorCodeNotFound APIErrorCode = "not-found"
+
+	// APIErrorCodeRateLimited is returned if the rate limit was exceeded.
+	APIErrorCodeRateLimited APIErrorCode = "rate-limited"
+
+	// APIErrorCodeInvalidRequest is returned if the API rejected the parameters of the request.
+	APIErrorCodeInvalidRequest APIErrorCode = "invalid-request"
+
+	// APIErrorCodeUnknown is used for all other errors.
+	APIErrorCodeUnknown APIErrorCode = "unknown"
+)
+
+// ErrDeviceBusy indicates that the device cannot be changed, because another task runs on it, e.g. a reload.
+var ErrDeviceBusy = fmt.Errorf("device is busy")
+
+// powerStateMessages maps parts of the messages of 400 Bad Request responses to error codes, because the API reports
+// the power state of a device only in the message. Messages are compared in lower case. The messages are not verified
+// against captured responses of the API, see testdata/apierrors/README.md.
+var powerStateMessages = []struct {
+	substring string
+	code      APIErrorCode
+}{
+	{"while server is powered off", APIErrorCodeDevicePoweredOff},
+	{"server is not powered on", APIErrorCodeDevicePoweredOff},
+	{"while server is powered on", APIErrorCodeDevicePoweredOn},
+	{"server is already powered on", APIErrorCodeDevicePoweredOn},
+}
+
+// APIError is an error response of the Hivelocity API with a structured error code.
+// errors.Is matches the typed errors of this package, e.g. ErrDevicePoweredOff for APIErrorCodeDevicePoweredOff.
+type APIError struct {
+	StatusCode int
+	Code       APIErrorCode
+	Message    string
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("hivelocity API error %d (%s): %s", e.StatusCode, e.Code, e.Err)
+	}
+	return fmt.Sprintf("hivelocity API error %d (%s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Unwrap returns the error of the generated client.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if the target is the typed error of the error code.
+func (e *APIError) Is(target error) bool {
+	switch e.Code {
+	case APIErrorCodeDevicePoweredOff:
+		return target == ErrDevicePoweredOff
+	case APIErrorCodeDevicePoweredOn:
+		return target == ErrDeviceTurnedOnAlready
+	case APIErrorCodeDeviceBusy:
+		return target == ErrDeviceBusy
+	case APIErrorCodeRateLimited:
+		return target == ErrRateLimitExceeded
+	default:
+		return false
+	}
+}
+
+// apiErrorBody contains the fields which the API uses for error messages.
+type apiErrorBody struct {
+	Code        int               `json:"code"`
+	Message     string            `json:"message"`
+	Description string            `json:"description"`
+	Error       string            `json:"error"`
+	Errors      map[string]string `json:"errors"`
+}
+
+// parseAPIError converts an error response of the API to an APIError.
+// Nil is returned if the error is not an error response, e.g. a network error.
+func parseAPIError(err error) *APIError {
+	var swaggerErr hv.GenericSwaggerError
+	if !errors.As(err, &swaggerErr) {
+		return nil
+	}
+	return newAPIError(statusCodeOf(err), swaggerErr.Body(), err)
+}
+
+// newAPIError returns the APIError of a response with the status code and the body.
+func newAPIError(statusCode int, body []byte, err error) *APIError {
+	var parsed apiErrorBody
+	_ = json.Unmarshal(body, &parsed)
+	message := messageOfBody(body)
+	return &APIError{
+		StatusCode: statusCode,
+		Code:       codeOf(statusCode, parsed.Code, message),
+		Message:    message,
+		Err:        err,
+	}
+}
+
+// messageOfBody returns the error message of the body of an error response.
+// Bodies which are no JSON object are returned as they are.
+func messageOfBody(body []byte) string {
+	var parsed apiErrorBody
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+
+	var parts []string
+	for _, s := range []string{parsed.Message, parsed.Description, parsed.Error} {
+		if s != "" && !slices.Contains(parts, s) {
+			parts = append(parts, s)
+		}
+	}
+	fields := maps.Keys(parsed.Errors)
+	sort.Strings(fields)
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", field, parsed.Errors[field]))
+	}
+	return strings.Join(parts, ": ")
+}
+
+// codeOf returns the error code of a response with the status code, the status in the code field of the body and
+// the error message. 409 Conflict is assumed to mean that another task runs on the device, e.g. a reload. This is not
+// verified against captured responses of the API yet.
+func codeOf(statusCode, bodyCode int, message string) APIErrorCode {
+	if bodyCode == 0 {
+		bodyCode = statusCode
+	}
+	hasStatus := func(status int) bool {
+		return statusCode == status || bodyCode == status
+	}
+
+	if hasStatus(http.StatusBadRequest) {
+		lower := strings.ToLower(message)
+		for _, m := range powerStateMessages {
+			if strings.Contains(lower, m.substring) {
+				return m.code
+			}
+		}
+	}
+	if hasStatus(http.StatusConflict) {
+		return APIErrorCodeDeviceBusy
+	}
+
+	switch categoryOfStatusCode(statusCode) {
+	case ErrorCategoryNotFound:
+		return APIErrorCodeNotFound
+	case ErrorCategoryRateLimit:
+		return APIErrorCodeRateLimited
+	case ErrorCategoryPermanent:
+		if statusCode >= 400 && statusCode < 500 {
+			return APIErrorCodeInvalidRequest
+		}
+	}
+	return APIErrorCodeUnknown
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServerClient returns a client for a server which answers all requests with the status code and the fixture.
+func newTestServerClient(t *testing.T, statusCode int, fixture string) *realClient {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "apierrors", fixture))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	config := hv.NewConfiguration()
+	config.BasePath = server.URL
+	return &realClient{client: hv.NewAPIClient(config), config: config, inventory: newDeviceInventory("test"), nullRoutes: newNullRouteCache()}
+}
+
+func Test_parseAPIError(t *testing.T) {
+	for _, tc := range []struct {
+		fixture    string
+		statusCode int
+		code       APIErrorCode
+		message    string
+	}{
+		{"powered-off.json", http.StatusBadRequest, APIErrorCodeDevicePoweredOff, "Can't do this while server is powered off."},
+		{"powered-on.json", http.StatusBadRequest, APIErrorCodeDevicePoweredOn, "Can't do this while server is powered on."},
+		{"reload-in-progress.json", http.StatusConflict, APIErrorCodeDeviceBusy, "Device reload in progress. Please try again later."},
+		{"pending-task.json", http.StatusConflict, APIErrorCodeDeviceBusy, "Conflict: There is a pending task for this device."},
+		{"not-found.json", http.StatusNotFound, APIErrorCodeNotFound, "Device not found"},
+		{
+			"validation-failed.json", http.StatusBadRequest, APIErrorCodeInvalidRequest,
+			"Input payload validation failed: hostname: '' is too short: osName: 'Foo' is not a valid operating system",
+		},
+		{"rate-limited.json", http.StatusTooManyRequests, APIErrorCodeRateLimited, "Too many requests"},
+		{"bad-gateway.html", http.StatusBadGateway, APIErrorCodeUnknown, "<html><body><h1>502 Bad Gateway</h1></body></html>"},
+	} {
+		t.Run(tc.fixture, func(t *testing.T) {
+			c := newTestServerClient(t, tc.statusCode, tc.fixture)
+			_, _, err := c.client.DeviceApi.PostPowerResource(context.Background(), 1, powerActionBoot, nil) //nolint:bodyclose // Close() gets done in client
+
+			apiErr := parseAPIError(err)
+			require.NotNil(t, apiErr)
+			require.Equal(t, tc.statusCode, apiErr.StatusCode)
+			require.Equal(t, tc.code, apiErr.Code)
+			require.Equal(t, tc.message, apiErr.Message)
+		})
+	}
+}
+
+func Test_codeOf(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		statusCode int
+		bodyCode   int
+		message    string
+		code       APIErrorCode
+	}{
+		{"conflict", http.StatusConflict, 0, "Conflict", APIErrorCodeDeviceBusy},
+		{"conflict in body", http.StatusBadRequest, http.StatusConflict, "Conflict", APIErrorCodeDeviceBusy},
+		{"powered off", http.StatusBadRequest, 0, "Can't do this while server is powered off.", APIErrorCodeDevicePoweredOff},
+		// messages alone don't make a device busy
+		{"please wait", http.StatusBadRequest, 0, "Invalid hostname, please wait for the DNS check", APIErrorCodeInvalidRequest},
+		{"reload in progress", http.StatusInternalServerError, 0, "Reload in progress", APIErrorCodeUnknown},
+		// power state messages only count for bad requests
+		{"powered off message in server error", http.StatusInternalServerError, 0, "while server is powered off", APIErrorCodeUnknown},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.code, codeOf(tc.statusCode, tc.bodyCode, tc.message))
+		})
+	}
+}
+
+func Test_parseAPIError_noResponse(t *testing.T) {
+	require.Nil(t, parseAPIError(errors.New("connection refused")))
+}
+
+func Test_powerActionErrors(t *testing.T) {
+	ctx := context.Background()
+
+	c := newTestServerClient(t, http.StatusBadRequest, "powered-off.json")
+	require.ErrorIs(t, c.ShutdownDevice(ctx, 1), ErrDeviceShutDownAlready)
+	require.ErrorIs(t, c.RebootDevice(ctx, 1), ErrDevicePoweredOff)
+
+	c = newTestServerClient(t, http.StatusBadRequest, "powered-on.json")
+	require.Equal(t, ErrDeviceTurnedOnAlready, c.PowerOnDevice(ctx, 1))
+
+	c = newTestServerClient(t, http.StatusConflict, "reload-in-progress.json")
+	err := c.RebootDevice(ctx, 1)
+	require.ErrorIs(t, err, ErrDeviceBusy)
+	var powerErr *PowerActionError
+	require.ErrorAs(t, err, &powerErr)
+	require.Equal(t, "Device reload in progress. Please try again later.", powerErr.Message)
+	require.Equal(t, ErrorCategoryConflict, CategoryOf(err))
+}
+
+func Test_provisionDeviceErrors(t *testing.T) {
+	c := newTestServerClient(t, http.StatusConflict, "pending-task.json")
+	_, err := c.ProvisionDevice(context.Background(), 1, hv.BareMetalDeviceUpdate{})
+	require.ErrorIs(t, err, ErrDeviceBusy)
+
+	c = newTestServerClient(t, http.StatusBadRequest, "validation-failed.json")
+	_, err = c.ProvisionDevice(context.Background(), 1, hv.BareMetalDeviceUpdate{})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, APIErrorCodeInvalidRequest, apiErr.Code)
+	require.Equal(t, ErrorCategoryPermanent, CategoryOf(err))
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+// ErrorCategory classifies errors of the Hivelocity API, so that callers can decide how to handle them.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryNone is the category of a nil error.
+	ErrorCategoryNone ErrorCategory = ""
+
+	// ErrorCategoryTransient is used for server errors and network failures. The call can be retried later.
+	ErrorCategoryTransient ErrorCategory = "transient"
+
+	// ErrorCategoryRateLimit is used if the rate limit of the API was exceeded. The call can be retried later.
+	ErrorCategoryRateLimit ErrorCategory = "rate-limit"
+
+	// ErrorCategoryNotFound is used if the resource does not exist.
+	ErrorCategoryNotFound ErrorCategory = "not-found"
+
+	// ErrorCategoryConflict is used if the call is not possible in the current state of the resource,
+	// for example powering on a device which is turned on already.
+	ErrorCategoryConflict ErrorCategory = "conflict"
+
+	// ErrorCategoryPermanent is used for all other errors, for example invalid requests. Retrying does not help.
+	ErrorCategoryPermanent ErrorCategory = "permanent"
+)
+
+// CategoryOf returns the category of an error returned by the Client.
+func CategoryOf(err error) ErrorCategory {
+	switch {
+	case err == nil:
+		return ErrorCategoryNone
+	case errors.Is(err, ErrRateLimitExceeded):
+		return ErrorCategoryRateLimit
+	case errors.Is(err, ErrDeviceNotFound),
+		errors.Is(err, ErrNetworkTaskNotFound),
+		errors.Is(err, ErrTicketNotFound),
+		errors.Is(err, ErrDNSZoneNotFound):
+		return ErrorCategoryNotFound
+	case errors.Is(err, ErrDeviceShutDownAlready),
+		errors.Is(err, ErrDeviceTurnedOnAlready),
+		errors.Is(err, ErrDevicePoweredOff),
+		errors.Is(err, ErrDeviceBusy):
+		return ErrorCategoryConflict
+	}
+
+	if statusCode := statusCodeOf(err); statusCode != 0 {
+		return categoryOfStatusCode(statusCode)
+	}
+
+	if isNetworkError(err) {
+		return ErrorCategoryTransient
+	}
+	return ErrorCategoryPermanent
+}
+
+// categoryOfStatusCode returns the category of a failed response of the API.
+func categoryOfStatusCode(statusCode int) ErrorCategory {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorCategoryRateLimit
+	case statusCode == http.StatusNotFound:
+		return ErrorCategoryNotFound
+	case statusCode == http.StatusConflict:
+		return ErrorCategoryConflict
+	case statusCode == http.StatusRequestTimeout, statusCode >= http.StatusInternalServerError:
+		return ErrorCategoryTransient
+	default:
+		return ErrorCategoryPermanent
+	}
+}
+
+// statusCodeOf returns the HTTP status code of an error response of the API, or zero if the error has none.
+// Requests which bypass the generated client return an *APIError with the status code.
+// The status of a GenericSwaggerError is the status line of the response, for example "404 NOT FOUND".
+func statusCodeOf(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	var swaggerErr hv.GenericSwaggerError
+	if !errors.As(err, &swaggerErr) {
+		return 0
+	}
+	code, _, _ := strings.Cut(swaggerErr.Error(), " ")
+	statusCode, err := strconv.Atoi(code)
+	if err != nil {
+		return 0
+	}
+	return statusCode
+}
+
+// isNetworkError returns true if the request failed without a response of the API.
+// Errors of the context are not network errors, because retrying does not help.
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
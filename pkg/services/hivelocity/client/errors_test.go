@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+// swaggerError returns the error of the generated client for a response with the status code.
+func swaggerError(t *testing.T, statusCode int) error {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	config := hv.NewConfiguration()
+	config.BasePath = server.URL
+	_, _, err := hv.NewAPIClient(config).BareMetalDevicesApi.GetBareMetalDeviceIdResource(context.Background(), 1, nil) //nolint:bodyclose // Close() gets done in client
+	require.Error(t, err)
+	return err
+}
+
+func TestCategoryOf(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected ErrorCategory
+	}{
+		{"nil", nil, ErrorCategoryNone},
+		{"rate limit", ErrRateLimitExceeded, ErrorCategoryRateLimit},
+		{"device not found", fmt.Errorf("wrapped: %w", ErrDeviceNotFound), ErrorCategoryNotFound},
+		{"shut down already", ErrDeviceShutDownAlready, ErrorCategoryConflict},
+		{"power action", &PowerActionError{Err: swaggerError(t, http.StatusBadRequest)}, ErrorCategoryPermanent},
+		{"status 404", swaggerError(t, http.StatusNotFound), ErrorCategoryNotFound},
+		{"status 409", swaggerError(t, http.StatusConflict), ErrorCategoryConflict},
+		{"status 429", swaggerError(t, http.StatusTooManyRequests), ErrorCategoryRateLimit},
+		{"status 503", swaggerError(t, http.StatusServiceUnavailable), ErrorCategoryTransient},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorCategoryTransient},
+		{"context", context.DeadlineExceeded, ErrorCategoryPermanent},
+		{"other", errors.New("invalid"), ErrorCategoryPermanent},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, CategoryOf(tc.err))
+		})
+	}
+}
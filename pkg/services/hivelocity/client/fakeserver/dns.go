@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+// AddDomain adds a DNS zone and returns its ID.
+func (s *Server) AddDomain(name string) int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	domain := hv.DomainReturn{DomainId: s.nextIDLocked(), Name: name}
+	s.domains = append(s.domains, domain)
+	return domain.DomainId
+}
+
+// AddPTRRecord adds a PTR record. Hivelocity creates them for the IPs of devices.
+func (s *Server) AddPTRRecord(record hv.PtrRecordReturn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ptrRecords = append(s.ptrRecords, record)
+}
+
+// ARecords returns the A records of the zone.
+func (s *Server) ARecords(zone string) []hv.ARecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]hv.ARecord(nil), s.aRecords[zone]...)
+}
+
+// AAAARecords returns the AAAA records of the zone.
+func (s *Server) AAAARecords(zone string) []hv.AaaaRecordReturn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	domain, ok := s.domainByNameLocked(zone)
+	if !ok {
+		return nil
+	}
+	return append([]hv.AaaaRecordReturn(nil), s.aaaaRecords[domain.DomainId]...)
+}
+
+// PTRRecords returns all PTR records.
+func (s *Server) PTRRecords() []hv.PtrRecordReturn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]hv.PtrRecordReturn(nil), s.ptrRecords...)
+}
+
+func (s *Server) domainByNameLocked(name string) (hv.DomainReturn, bool) {
+	for _, domain := range s.domains {
+		if domain.Name == name {
+			return domain, true
+		}
+	}
+	return hv.DomainReturn{}, false
+}
+
+func (s *Server) domainByIDLocked(id string) (hv.DomainReturn, bool) {
+	domainID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return hv.DomainReturn{}, false
+	}
+	for _, domain := range s.domains {
+		if domain.DomainId == int32(domainID) {
+			return domain, true
+		}
+	}
+	return hv.DomainReturn{}, false
+}
+
+func (s *Server) handleDomains(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case r.Method == http.MethodGet && len(segments) == 1:
+		writeJSON(w, http.StatusOK, nonNil(s.domains))
+	case len(segments) >= 2 && segments[1] == "ptr":
+		s.handlePTRRecords(w, r, segments[2:])
+	case len(segments) >= 3 && segments[2] == "a-record":
+		domain, ok := s.domainByNameLocked(segments[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, msgNotFound)
+			return
+		}
+		s.handleARecords(w, r, domain.Name, segments[3:])
+	case len(segments) >= 3 && segments[2] == "aaaa-record":
+		domain, ok := s.domainByIDLocked(segments[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, msgNotFound)
+			return
+		}
+		s.handleAAAARecords(w, r, domain, segments[3:])
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
+
+// handleARecords handles the A records of the zone. A records are addressed by their name.
+func (s *Server) handleARecords(w http.ResponseWriter, r *http.Request, zone string, segments []string) {
+	records := s.aRecords[zone]
+	switch {
+	case r.Method == http.MethodGet && len(segments) == 0:
+		writeJSON(w, http.StatusOK, nonNil(records))
+	case r.Method == http.MethodPost && len(segments) == 0:
+		var record hv.ARecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.aRecords[zone] = append(records, record)
+		writeJSON(w, http.StatusCreated, record)
+	case (r.Method == http.MethodPut || r.Method == http.MethodDelete) && len(segments) == 1:
+		for i, record := range records {
+			if record.Name != segments[0] {
+				continue
+			}
+			if r.Method == http.MethodDelete {
+				s.aRecords[zone] = append(records[:i], records[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			if err := json.NewDecoder(r.Body).Decode(&records[i]); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, records[i])
+			return
+		}
+		writeError(w, http.StatusNotFound, msgNotFound)
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
+
+// handleAAAARecords handles the AAAA records of the zone. Unlike A records, they are addressed by their ID.
+func (s *Server) handleAAAARecords(w http.ResponseWriter, r *http.Request, domain hv.DomainReturn, segments []string) {
+	records := s.aaaaRecords[domain.DomainId]
+	switch {
+	case r.Method == http.MethodGet && len(segments) == 0:
+		writeJSON(w, http.StatusOK, nonNil(records))
+	case r.Method == http.MethodPost && len(segments) == 0:
+		var create hv.AaaaRecordCreate
+		if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		record := hv.AaaaRecordReturn{
+			Id:       s.nextIDLocked(),
+			Type_:    "AAAA",
+			Name:     create.Name,
+			Address:  create.Address,
+			Ttl:      create.Ttl,
+			DomainId: domain.DomainId,
+		}
+		s.aaaaRecords[domain.DomainId] = append(records, record)
+		writeJSON(w, http.StatusCreated, record)
+	case r.Method == http.MethodDelete && len(segments) == 1:
+		for i, record := range records {
+			if strconv.Itoa(int(record.Id)) == segments[0] {
+				s.aaaaRecords[domain.DomainId] = append(records[:i], records[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, msgNotFound)
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
+
+// handlePTRRecords lists and updates PTR records. They can't be created or deleted.
+func (s *Server) handlePTRRecords(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case r.Method == http.MethodGet && len(segments) == 0:
+		writeJSON(w, http.StatusOK, nonNil(s.ptrRecords))
+	case r.Method == http.MethodPut && len(segments) == 1:
+		for i, record := range s.ptrRecords {
+			if strconv.Itoa(int(record.Id)) != segments[0] {
+				continue
+			}
+			var update hv.PtrRecordUpdate
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.ptrRecords[i].Name = update.Name
+			if update.Ttl != 0 {
+				s.ptrRecords[i].Ttl = update.Ttl
+			}
+			writeJSON(w, http.StatusOK, s.ptrRecords[i])
+			return
+		}
+		writeError(w, http.StatusNotFound, msgNotFound)
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
@@ -0,0 +1,588 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakeserver implements a fake of the Hivelocity API on top of httptest.
+// It keeps devices, network tasks, tickets and DNS records in memory and simulates power transitions, reloads,
+// rate limits and error responses, so that the real client and the controllers can be tested without an account.
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+// BasePath is the path prefix of all endpoints, like in the Hivelocity API.
+const BasePath = "/api/v2"
+
+// Messages of error responses. They are the messages which the client parses into error codes.
+const (
+	msgPoweredOff       = "Can't do this while server is powered off."
+	msgPoweredOn        = "Can't do this while server is powered on."
+	msgReloadInProgress = "Device reload in progress. Please try again later."
+	msgDeviceNotFound   = "Device not found"
+	msgNotFound         = "The requested URL was not found on the server."
+	msgUnauthorized     = "Unauthorized"
+	msgTooManyRequests  = "Too many requests"
+)
+
+// device is a device of the fake server.
+type device struct {
+	hv.BareMetalDevice
+
+	poweredOn bool
+
+	// powerTarget is the power state after a power action. It is reached at powerAt.
+	powerTarget *bool
+	powerAt     time.Time
+
+	// reloadUntil is the end of the running reload.
+	reloadUntil time.Time
+
+	ipAssignments []hv.IpAssignment
+	ports         []hv.DevicePort
+	bonded        bool
+	events        []hv.DeviceEvent
+	ipmiSensors   []IPMISensor
+	ipmiWhitelist []string
+}
+
+// IPMISensor is an IPMI sensor as returned by the API. Status is omitted for sensors without status.
+type IPMISensor struct {
+	SensorID string  `json:"sensorId,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Group    string  `json:"group,omitempty"`
+	Units    string  `json:"units,omitempty"`
+	Reading  float32 `json:"reading,omitempty"`
+	Status   *bool   `json:"status,omitempty"`
+}
+
+// injectedError is the response for the next request which matches the method and path.
+type injectedError struct {
+	method     string
+	path       string
+	statusCode int
+	body       string
+}
+
+// Server is a fake of the Hivelocity API. Create it with NewServer and point the client to URL().
+type Server struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	devices  map[int32]*device
+	sshKeys  []hv.SshKeyResponse
+	images   map[int32][]string
+	apiKey   string
+	offset   time.Duration
+	requests []string
+
+	reloadDuration          time.Duration
+	powerTransitionDuration time.Duration
+
+	nullRoutes   []hv.NullRoute
+	networkTasks map[string]hv.NetworkTaskDump
+	tickets      []hv.Ticket
+	domains      []hv.DomainReturn
+	aRecords     map[string][]hv.ARecord
+	aaaaRecords  map[int32][]hv.AaaaRecordReturn
+	ptrRecords   []hv.PtrRecordReturn
+	lastID       int32
+
+	rateLimited int
+	retryAfter  time.Duration
+	errors      []injectedError
+}
+
+// NewServer starts a fake server without devices. Reloads and power transitions finish immediately,
+// unless durations are set with SetReloadDuration and SetPowerTransitionDuration.
+func NewServer() *Server {
+	s := &Server{
+		devices:      make(map[int32]*device),
+		images:       make(map[int32][]string),
+		networkTasks: make(map[string]hv.NetworkTaskDump),
+		aRecords:     make(map[string][]hv.ARecord),
+		aaaaRecords:  make(map[int32][]hv.AaaaRecordReturn),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL returns the base URL of the API, which can be used as BasePath of the client.
+func (s *Server) URL() string {
+	return s.server.URL + BasePath
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetAPIKey makes the server reject requests with another X-API-KEY header with 401. By default, all keys are accepted.
+func (s *Server) SetAPIKey(apiKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = apiKey
+}
+
+// AddDevice adds a device. It is powered on if its PowerStatus is "ON".
+func (s *Server) AddDevice(d hv.BareMetalDevice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[d.DeviceId] = &device{BareMetalDevice: d, poweredOn: d.PowerStatus == powerOn}
+}
+
+// AddSSHKey adds an SSH key.
+func (s *Server) AddSSHKey(key hv.SshKeyResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sshKeys = append(s.sshKeys, key)
+}
+
+// SetImages sets the names of the operating systems which can be installed on devices of the product.
+func (s *Server) SetImages(productID int32, names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.images[productID] = names
+}
+
+// SetIPAssignments sets the IP assignments of the device. It does nothing if the device does not exist.
+func (s *Server) SetIPAssignments(deviceID int32, assignments ...hv.IpAssignment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.devices[deviceID]; ok {
+		d.ipAssignments = assignments
+	}
+}
+
+// SetPorts sets the ports of the device. A bond port is added while the ports of the device are bonded.
+func (s *Server) SetPorts(deviceID int32, ports ...hv.DevicePort) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.devices[deviceID]; ok {
+		d.ports = ports
+	}
+}
+
+// SetIPMISensors sets the IPMI sensors of the device. Devices have no sensors by default.
+func (s *Server) SetIPMISensors(deviceID int32, sensors ...IPMISensor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.devices[deviceID]; ok {
+		d.ipmiSensors = sensors
+	}
+}
+
+// IPMIWhitelist returns the IPs which were whitelisted for IPMI access to the device.
+func (s *Server) IPMIWhitelist(deviceID int32) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.devices[deviceID]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), d.ipmiWhitelist...)
+}
+
+// Events returns the events of the device. Provisioning and power actions add events.
+func (s *Server) Events(deviceID int32) []hv.DeviceEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.devices[deviceID]
+	if !ok {
+		return nil
+	}
+	return append([]hv.DeviceEvent(nil), d.events...)
+}
+
+// SetReloadDuration sets the time a device reloads after it was provisioned.
+func (s *Server) SetReloadDuration(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadDuration = d
+}
+
+// SetPowerTransitionDuration sets the time until a power action changes the power state of a device.
+func (s *Server) SetPowerTransitionDuration(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.powerTransitionDuration = d
+}
+
+// Advance moves the clock of the server forward, so that reloads and power transitions finish without waiting.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset += d
+}
+
+// InjectRateLimit answers the next n requests with 429 Too Many Requests and the Retry-After header.
+func (s *Server) InjectRateLimit(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimited = n
+	s.retryAfter = retryAfter
+}
+
+// InjectError answers the next request with the method and path (without BasePath) with the status code and body.
+func (s *Server) InjectError(method, path string, statusCode int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, injectedError{method: method, path: path, statusCode: statusCode, body: body})
+}
+
+// Device returns the current state of the device.
+func (s *Server) Device(deviceID int32) (hv.BareMetalDevice, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.devices[deviceID]
+	if !ok {
+		return hv.BareMetalDevice{}, false
+	}
+	s.updateLocked(d)
+	return d.view(), true
+}
+
+// IsReloading returns true if the device is reloading.
+func (s *Server) IsReloading(deviceID int32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.devices[deviceID]
+	return ok && s.isReloadingLocked(d)
+}
+
+// Requests returns the method and path of all requests, for example "PUT /device/1/tags".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+const (
+	powerOn  = "ON"
+	powerOff = "OFF"
+
+	portTypeBond = "bond"
+)
+
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.offset)
+}
+
+// nextIDLocked returns a new ID for tickets, network tasks and DNS records.
+func (s *Server) nextIDLocked() int32 {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *Server) addEventLocked(d *device, action string) {
+	d.events = append(d.events, hv.DeviceEvent{Action: action, Time: int32(s.now().Unix())})
+}
+
+// updateLocked applies the power transitions and reloads which finished.
+func (s *Server) updateLocked(d *device) {
+	now := s.now()
+	if d.powerTarget != nil && !now.Before(d.powerAt) {
+		d.poweredOn = *d.powerTarget
+		d.powerTarget = nil
+	}
+	if !d.reloadUntil.IsZero() && !now.Before(d.reloadUntil) {
+		// the device boots after the operating system was installed.
+		d.reloadUntil = time.Time{}
+		d.poweredOn = true
+	}
+}
+
+func (s *Server) isReloadingLocked(d *device) bool {
+	s.updateLocked(d)
+	return !d.reloadUntil.IsZero()
+}
+
+// view returns the device as returned by the API.
+func (d *device) view() hv.BareMetalDevice {
+	view := d.BareMetalDevice
+	view.Tags = append([]string(nil), d.Tags...)
+	view.PowerStatus = powerOff
+	if d.poweredOn {
+		view.PowerStatus = powerOn
+	}
+	return view
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, BasePath)
+	s.requests = append(s.requests, r.Method+" "+path)
+
+	if s.apiKey != "" && r.Header.Get("X-API-KEY") != s.apiKey {
+		writeError(w, http.StatusUnauthorized, msgUnauthorized)
+		return
+	}
+	if s.rateLimited > 0 {
+		s.rateLimited--
+		w.Header().Set("Retry-After", strconv.Itoa(int(s.retryAfter.Seconds())))
+		writeError(w, http.StatusTooManyRequests, msgTooManyRequests)
+		return
+	}
+	for i, e := range s.errors {
+		if e.method == r.Method && e.path == path {
+			s.errors = append(s.errors[:i], s.errors[i+1:]...)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(e.statusCode)
+			_, _ = w.Write([]byte(e.body))
+			return
+		}
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && path == "/bare-metal-devices/":
+		s.listDevices(w)
+	case r.Method == http.MethodGet && path == "/ssh_key/":
+		writeJSON(w, http.StatusOK, s.sshKeys)
+	case r.Method == http.MethodGet && path == "/profile/basic":
+		writeJSON(w, http.StatusOK, hv.BasicProfileDump{Email: "fake@example.com"})
+	case len(segments) == 3 && segments[0] == "product" && segments[2] == "operating-systems" && r.Method == http.MethodGet:
+		s.listImages(w, segments[1])
+	case len(segments) >= 2 && (segments[0] == "bare-metal-devices" || segments[0] == "device"):
+		s.handleDevice(w, r, segments)
+	case segments[0] == "network":
+		s.handleNetwork(w, r, segments)
+	case segments[0] == "tickets":
+		s.handleTickets(w, r, segments)
+	case segments[0] == "domains":
+		s.handleDomains(w, r, segments)
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
+
+func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request, segments []string) {
+	deviceID, err := strconv.ParseInt(segments[1], 10, 32)
+	if err != nil {
+		writeError(w, http.StatusNotFound, msgNotFound)
+		return
+	}
+	d, ok := s.devices[int32(deviceID)]
+	if !ok {
+		writeError(w, http.StatusNotFound, msgDeviceNotFound)
+		return
+	}
+	s.updateLocked(d)
+
+	resource := segments[0]
+	if len(segments) > 2 {
+		resource += "/" + strings.Join(segments[2:], "/")
+	}
+
+	switch r.Method + " " + resource {
+	case "GET bare-metal-devices":
+		writeJSON(w, http.StatusOK, d.view())
+	case "PUT bare-metal-devices":
+		s.provisionDevice(w, r, d)
+	case "GET device":
+		writeJSON(w, http.StatusOK, s.deviceDump(d))
+	case "PUT device/tags":
+		var tags hv.DeviceTag
+		if err := json.NewDecoder(r.Body).Decode(&tags); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		d.Tags = tags.Tags
+		writeJSON(w, http.StatusOK, hv.DeviceTag{Tags: d.Tags})
+	case "GET device/power":
+		writeJSON(w, http.StatusOK, hv.DevicePower{PowerStatus: d.view().PowerStatus})
+	case "POST device/power":
+		s.powerAction(w, d, r.URL.Query().Get("action"))
+	case "GET device/ips":
+		writeJSON(w, http.StatusOK, nonNil(d.ipAssignments))
+	case "GET device/ports":
+		writeJSON(w, http.StatusOK, d.portsView())
+	case "POST device/ports/bond":
+		d.bonded = true
+		writeJSON(w, http.StatusOK, s.startNetworkTaskLocked(d.DeviceId))
+	case "DELETE device/ports/bond":
+		d.bonded = false
+		writeJSON(w, http.StatusOK, s.startNetworkTaskLocked(d.DeviceId))
+	case "GET device/events":
+		writeJSON(w, http.StatusOK, nonNil(d.events))
+	case "GET device/ipmi":
+		writeJSON(w, http.StatusOK, map[string][]IPMISensor{"sensors": nonNil(d.ipmiSensors)})
+	case "GET device/ipmi/nat":
+		// the client does not read the NAT rule, so the rule is not simulated.
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	case "POST device/ipmi/whitelist":
+		var whitelist hv.DeviceIpmiWhitelistIp
+		if err := json.NewDecoder(r.Body).Decode(&whitelist); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		d.ipmiWhitelist = append(d.ipmiWhitelist, whitelist.CustIp)
+		writeJSON(w, http.StatusOK, whitelist)
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
+
+func (s *Server) listDevices(w http.ResponseWriter) {
+	ids := make([]int32, 0, len(s.devices))
+	for id := range s.devices {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	devices := make([]hv.BareMetalDevice, 0, len(ids))
+	for _, id := range ids {
+		d := s.devices[id]
+		s.updateLocked(d)
+		devices = append(devices, d.view())
+	}
+	writeJSON(w, http.StatusOK, devices)
+}
+
+func (s *Server) listImages(w http.ResponseWriter, productID string) {
+	id, err := strconv.ParseInt(productID, 10, 32)
+	if err != nil {
+		writeError(w, http.StatusNotFound, msgNotFound)
+		return
+	}
+	options := make([]hv.OptionDump, 0, len(s.images[int32(id)]))
+	for _, name := range s.images[int32(id)] {
+		options = append(options, hv.OptionDump{Name: name})
+	}
+	writeJSON(w, http.StatusOK, options)
+}
+
+// provisionDevice starts a reload of the device. The device has to be powered off.
+func (s *Server) provisionDevice(w http.ResponseWriter, r *http.Request, d *device) {
+	var update hv.BareMetalDeviceUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if s.isReloadingLocked(d) {
+		writeError(w, http.StatusConflict, msgReloadInProgress)
+		return
+	}
+	if d.poweredOn {
+		writeError(w, http.StatusBadRequest, msgPoweredOn)
+		return
+	}
+
+	d.OsName = update.OsName
+	d.Hostname = update.Hostname
+	d.Script = update.Script
+	d.PublicSshKeyId = update.PublicSshKeyId
+	if update.Tags != nil {
+		d.Tags = update.Tags
+	}
+	d.powerTarget = nil
+	d.reloadUntil = s.now().Add(s.reloadDuration)
+	s.addEventLocked(d, "Device reload")
+	s.updateLocked(d)
+	writeJSON(w, http.StatusOK, d.view())
+}
+
+// powerAction changes the power state of the device after the power transition duration.
+func (s *Server) powerAction(w http.ResponseWriter, d *device, action string) {
+	if s.isReloadingLocked(d) {
+		writeError(w, http.StatusConflict, msgReloadInProgress)
+		return
+	}
+
+	var target bool
+	switch action {
+	case "boot":
+		if d.poweredOn {
+			writeError(w, http.StatusBadRequest, msgPoweredOn)
+			return
+		}
+		target = true
+	case "shutdown", "off":
+		if !d.poweredOn {
+			writeError(w, http.StatusBadRequest, msgPoweredOff)
+			return
+		}
+		target = false
+	case "reboot", "cycle", "reset":
+		if !d.poweredOn {
+			writeError(w, http.StatusBadRequest, msgPoweredOff)
+			return
+		}
+		// the device is on again after the restart.
+		target = true
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown power action %q", action))
+		return
+	}
+
+	d.powerTarget = &target
+	d.powerAt = s.now().Add(s.powerTransitionDuration)
+	s.addEventLocked(d, "Power "+action)
+	s.updateLocked(d)
+	writeJSON(w, http.StatusOK, hv.DevicePower{PowerStatus: d.view().PowerStatus})
+}
+
+// portsView returns the ports of the device as returned by the API.
+func (d *device) portsView() []hv.DevicePort {
+	ports := append([]hv.DevicePort{}, d.ports...)
+	if d.bonded {
+		ports = append(ports, hv.DevicePort{DeviceId: d.DeviceId, Name: "bond0", Type_: portTypeBond, Status: "ENABLED"})
+	}
+	return ports
+}
+
+func (s *Server) deviceDump(d *device) hv.DeviceDump {
+	view := d.view()
+	return hv.DeviceDump{
+		DeviceId:    view.DeviceId,
+		Name:        view.Hostname,
+		Hostname:    view.Hostname,
+		PowerStatus: view.PowerStatus,
+		IsReload:    s.isReloadingLocked(d),
+		Tags:        view.Tags,
+		PrimaryIp:   view.PrimaryIp,
+	}
+}
+
+// nonNil returns an empty slice instead of nil, so that lists are encoded as [] like in the API.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error body like the Hivelocity API.
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{"code": statusCode, "message": message})
+}
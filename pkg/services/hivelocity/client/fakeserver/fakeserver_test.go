@@ -0,0 +1,243 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeserver_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/fakeserver"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+func newServerAndClient(t *testing.T, apiKey string) (*fakeserver.Server, hvclient.Client) {
+	t.Helper()
+	server := fakeserver.NewServer()
+	t.Cleanup(server.Close)
+	server.AddDevice(hv.BareMetalDevice{DeviceId: 1, ProductId: 100, PowerStatus: "ON", Hostname: "device-1"})
+	server.AddSSHKey(hv.SshKeyResponse{SshKeyId: 10, Name: "key"})
+	server.SetImages(100, "Ubuntu 20.x", "Ubuntu 22.x")
+
+	factory := &hvclient.HivelocityFactory{BaseURL: server.URL()}
+	return server, factory.NewClient(apiKey)
+}
+
+func TestServer_inventory(t *testing.T) {
+	ctx := context.Background()
+	server, client := newServerAndClient(t, "inventory")
+
+	devices, err := client.ListDevices(ctx)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	require.Equal(t, "ON", devices[0].PowerStatus)
+
+	keys, err := client.ListSSHKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []hv.SshKeyResponse{{SshKeyId: 10, Name: "key"}}, keys)
+
+	images, err := client.ListImages(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Ubuntu 20.x", "Ubuntu 22.x"}, images)
+
+	require.NoError(t, client.SetDeviceTags(ctx, 1, []string{"caphv-cluster-name=test"}))
+	device, ok := server.Device(1)
+	require.True(t, ok)
+	require.Equal(t, []string{"caphv-cluster-name=test"}, device.Tags)
+
+	_, err = client.GetDevice(ctx, 2)
+	require.ErrorIs(t, err, hvclient.ErrDeviceNotFound)
+}
+
+func TestServer_powerAndProvisioning(t *testing.T) {
+	ctx := context.Background()
+	server, client := newServerAndClient(t, "provisioning")
+	server.SetPowerTransitionDuration(time.Minute)
+	server.SetReloadDuration(20 * time.Minute)
+
+	// the device has to be off for provisioning
+	_, err := client.ProvisionDevice(ctx, 1, hv.BareMetalDeviceUpdate{OsName: "Ubuntu 20.x", Hostname: "machine"})
+	require.Equal(t, hvclient.ErrorCategoryConflict, hvclient.CategoryOf(err))
+
+	require.NoError(t, client.ShutdownDevice(ctx, 1))
+	power, err := client.GetDevicePowerStatus(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "ON", power)
+
+	server.Advance(time.Minute)
+	require.ErrorIs(t, client.ShutdownDevice(ctx, 1), hvclient.ErrDeviceShutDownAlready)
+
+	_, err = client.ProvisionDevice(ctx, 1, hv.BareMetalDeviceUpdate{OsName: "Ubuntu 20.x", Hostname: "machine"})
+	require.NoError(t, err)
+	dump, err := client.GetDeviceDump(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, dump.IsReload)
+	require.ErrorIs(t, client.PowerOnDevice(ctx, 1), hvclient.ErrDeviceBusy)
+
+	server.Advance(20 * time.Minute)
+	require.False(t, server.IsReloading(1))
+	device, _ := server.Device(1)
+	require.Equal(t, "ON", device.PowerStatus)
+	require.Equal(t, "machine", device.Hostname)
+	require.Equal(t, "Ubuntu 20.x", device.OsName)
+}
+
+func TestServer_injectedErrors(t *testing.T) {
+	ctx := context.Background()
+	server, client := newServerAndClient(t, "errors")
+
+	server.InjectRateLimit(1, 0)
+	_, err := client.ListSSHKeys(ctx)
+	require.ErrorIs(t, err, hvclient.ErrRateLimitExceeded)
+
+	// idempotent requests are retried after server errors
+	server.InjectError(http.MethodGet, "/bare-metal-devices/1", http.StatusServiceUnavailable, `{"message": "Service Unavailable"}`)
+	_, err = client.GetDevice(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"GET /ssh_key/",
+		"GET /bare-metal-devices/1",
+		"GET /bare-metal-devices/1",
+	}, server.Requests())
+
+	server.InjectError(http.MethodPost, "/device/1/power", http.StatusConflict, `{"message": "There is a pending task for this device."}`)
+	require.ErrorIs(t, client.RebootDevice(ctx, 1), hvclient.ErrDeviceBusy)
+}
+
+func TestServer_apiKey(t *testing.T) {
+	server, client := newServerAndClient(t, "wrong-key")
+	server.SetAPIKey("secret")
+
+	_, err := client.ListSSHKeys(context.Background())
+	require.Error(t, err)
+	require.Equal(t, hvclient.ErrorCategoryPermanent, hvclient.CategoryOf(err))
+	require.ErrorIs(t, client.ValidateAPIKey(context.Background()), hvclient.ErrInvalidAPIKey)
+
+	factory := &hvclient.HivelocityFactory{BaseURL: server.URL()}
+	require.NoError(t, factory.NewClient("secret").ValidateAPIKey(context.Background()))
+}
+
+func TestServer_network(t *testing.T) {
+	ctx := context.Background()
+	server, client := newServerAndClient(t, "network")
+	server.SetIPAssignments(1, hv.IpAssignment{Version: 4, Subnet: "10.0.0.0/30", UsableIps: []string{"10.0.0.2"}})
+	server.SetPorts(1, hv.DevicePort{PortId: 5, Name: "eth0", Ips: []hv.IpAssignment{{Version: 4, UsableIps: []string{"10.0.0.2"}}}})
+	server.AddNullRoute("10.0.0.3", "abuse")
+
+	assignments, err := client.ListDeviceIPAssignments(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+
+	task, err := client.BondDevicePorts(ctx, 1)
+	require.NoError(t, err)
+	task, err = client.GetNetworkTask(ctx, task.TaskId)
+	require.NoError(t, err)
+	require.Equal(t, hvclient.NetworkTaskResultSuccess, task.Result)
+	ports, err := client.ListDevicePorts(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, ports, 2)
+	require.Equal(t, hvclient.PortTypeBond, ports[1].Type_)
+
+	_, err = client.GetNetworkTask(ctx, "unknown")
+	require.ErrorIs(t, err, hvclient.ErrNetworkTaskNotFound)
+
+	nullRoutes, err := client.ListNullRoutes(ctx)
+	require.NoError(t, err)
+	require.Len(t, nullRoutes, 1)
+	require.Equal(t, "10.0.0.3", nullRoutes[0].Ip)
+
+	// devices without data return empty lists instead of not found
+	server.AddDevice(hv.BareMetalDevice{DeviceId: 2})
+	ports, err = client.ListDevicePorts(ctx, 2)
+	require.NoError(t, err)
+	require.Empty(t, ports)
+	_, err = client.ListDevicePorts(ctx, 3)
+	require.ErrorIs(t, err, hvclient.ErrDeviceNotFound)
+}
+
+func TestServer_ipmiAndEvents(t *testing.T) {
+	ctx := context.Background()
+	server, client := newServerAndClient(t, "ipmi")
+	healthy := true
+	server.SetIPMISensors(1, fakeserver.IPMISensor{SensorID: "1", Name: "FAN1", Status: &healthy}, fakeserver.IPMISensor{SensorID: "2"})
+
+	sensors, err := client.ListDeviceIPMISensors(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sensors, 2)
+	require.False(t, sensors[0].IsFailing())
+	require.Nil(t, sensors[1].Status)
+
+	require.NoError(t, client.AddIPMIWhitelistIP(ctx, 1, "192.0.2.1"))
+	require.Equal(t, []string{"192.0.2.1"}, server.IPMIWhitelist(1))
+
+	require.NoError(t, client.ShutdownDevice(ctx, 1))
+	events, err := client.ListDeviceEvents(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "Power shutdown", events[0].Action)
+}
+
+func TestServer_tickets(t *testing.T) {
+	ctx := context.Background()
+	server, client := newServerAndClient(t, "tickets")
+
+	ticketID, err := client.CreateTicket(ctx, "Device 1 needs a hardware check", "FAN1 failed")
+	require.NoError(t, err)
+	ticket, err := client.GetTicket(ctx, ticketID)
+	require.NoError(t, err)
+	require.False(t, hvclient.IsTicketResolved(ticket))
+
+	server.SetTicketStatus(ticketID, "Closed")
+	tickets, err := client.ListTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	require.True(t, hvclient.IsTicketResolved(tickets[0]))
+
+	_, err = client.GetTicket(ctx, ticketID+1)
+	require.ErrorIs(t, err, hvclient.ErrTicketNotFound)
+}
+
+func TestServer_dns(t *testing.T) {
+	ctx := context.Background()
+	server, client := newServerAndClient(t, "dns")
+	server.AddDomain("example.com")
+	server.AddPTRRecord(hv.PtrRecordReturn{Id: 7, Address: "10.0.0.2", Name: "unknown.hivelocity.net"})
+
+	require.NoError(t, client.CreateARecord(ctx, "example.com", hv.ARecord{Name: "node", Ttl: 300, Addresses: []string{"10.0.0.2"}}))
+	require.NoError(t, client.UpdateARecord(ctx, "example.com", hv.ARecord{Name: "node", Ttl: 300, Addresses: []string{"10.0.0.3"}}))
+	records, err := client.ListARecords(ctx, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []hv.ARecord{{Name: "node", Ttl: 300, Addresses: []string{"10.0.0.3"}}}, records)
+	require.NoError(t, client.DeleteARecord(ctx, "example.com", "node"))
+	require.Empty(t, server.ARecords("example.com"))
+
+	_, err = client.ListARecords(ctx, "unknown.com")
+	require.ErrorIs(t, err, hvclient.ErrDNSZoneNotFound)
+
+	require.NoError(t, client.CreateAAAARecord(ctx, "example.com", hv.AaaaRecordCreate{Name: "node", Ttl: 300, Address: "2001:db8::1"}))
+	aaaaRecords, err := client.ListAAAARecords(ctx, "example.com")
+	require.NoError(t, err)
+	require.Len(t, aaaaRecords, 1)
+	require.NoError(t, client.DeleteAAAARecord(ctx, "example.com", aaaaRecords[0].Id))
+	require.Empty(t, server.AAAARecords("example.com"))
+
+	require.NoError(t, client.UpdatePTRRecord(ctx, 7, hv.PtrRecordUpdate{Name: "node.example.com"}))
+	require.Equal(t, "node.example.com", server.PTRRecords()[0].Name)
+}
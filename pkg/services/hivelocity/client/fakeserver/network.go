@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+const networkTaskResultSuccess = "Success"
+
+// AddNullRoute null-routes the IP address.
+func (s *Server) AddNullRoute(ip, comment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nullRoutes = append(s.nullRoutes, hv.NullRoute{Ip: ip, Comment: comment, Created: s.now().UTC().Truncate(time.Second)})
+}
+
+// startNetworkTaskLocked returns a network task of the device. Network tasks succeed immediately.
+func (s *Server) startNetworkTaskLocked(deviceID int32) hv.NetworkTaskDump {
+	task := hv.NetworkTaskDump{
+		TaskId:   fmt.Sprintf("task-%d", s.nextIDLocked()),
+		DeviceId: deviceID,
+		Result:   networkTaskResultSuccess,
+	}
+	s.networkTasks[task.TaskId] = task
+	return task
+}
+
+func (s *Server) handleNetwork(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[1] == "null-route":
+		writeJSON(w, http.StatusOK, nonNil(s.nullRoutes))
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[1] == "status":
+		task, ok := s.networkTasks[segments[2]]
+		if !ok {
+			writeError(w, http.StatusNotFound, msgNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, task)
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+const ticketStatusOpen = "Open"
+
+// Tickets returns all support tickets.
+func (s *Server) Tickets() []hv.Ticket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]hv.Ticket(nil), s.tickets...)
+}
+
+// SetTicketStatus sets the status of the ticket, for example "Closed". It does nothing if the ticket does not exist.
+func (s *Server) SetTicketStatus(ticketID int32, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tickets {
+		if int32(s.tickets[i].Id) == ticketID {
+			s.tickets[i].Status = status
+		}
+	}
+}
+
+func (s *Server) handleTickets(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case r.Method == http.MethodGet && len(segments) == 1:
+		writeJSON(w, http.StatusOK, nonNil(s.tickets))
+	case r.Method == http.MethodPost && len(segments) == 1:
+		var create hv.TicketCreate
+		if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ticket := hv.Ticket{
+			Id:      float32(s.nextIDLocked()),
+			Status:  ticketStatusOpen,
+			Queue:   create.Queue,
+			Subject: create.Subject,
+			Body:    create.Body,
+		}
+		s.tickets = append(s.tickets, ticket)
+		writeJSON(w, http.StatusCreated, ticket)
+	case r.Method == http.MethodGet && len(segments) == 2:
+		id, err := strconv.ParseInt(segments[1], 10, 32)
+		if err != nil {
+			writeError(w, http.StatusNotFound, msgNotFound)
+			return
+		}
+		for _, ticket := range s.tickets {
+			if int32(ticket.Id) == int32(id) {
+				writeJSON(w, http.StatusOK, ticket)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, msgNotFound)
+	default:
+		writeError(w, http.StatusNotFound, msgNotFound)
+	}
+}
@@ -26,7 +26,10 @@ import (
 	"net/http"
 	"regexp"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/utils"
@@ -42,11 +45,82 @@ const PowerStatusOff = "OFF"
 // PowerStatusOn is "ON".
 const PowerStatusOn = "ON"
 
+const (
+	// NetworkTaskResultSuccess is the result of a successful network task.
+	NetworkTaskResultSuccess = "Success"
+
+	// NetworkTaskResultFailed is the result of a failed network task. Pending tasks have no result or "Pending".
+	NetworkTaskResultFailed = "Failed"
+)
+
+// TicketQueueSupport is the queue of support tickets.
+const TicketQueueSupport = "Support"
+
+// PortTypeBond is the type of a bond interface.
+const PortTypeBond = "bond"
+
+// Actions of the power API. The soft actions go through ACPI, the others through IPMI.
+const (
+	powerActionBoot     = "boot"
+	powerActionShutdown = "shutdown"
+	powerActionReboot   = "reboot"
+	powerActionOff      = "off"
+	powerActionCycle    = "cycle"
+	powerActionReset    = "reset"
+)
+
+// PowerActionError gets returned if the Hivelocity API rejects a power action.
+// If the API responded, Err is an *APIError, so that errors.Is matches its code, e.g. ErrDeviceBusy.
+type PowerActionError struct {
+	DeviceID int32
+	Action   string
+	Message  string
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *PowerActionError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("power action %q on device %d failed: %s", e.Action, e.DeviceID, e.Err)
+	}
+	return fmt.Sprintf("power action %q on device %d failed: %s: %s", e.Action, e.DeviceID, e.Message, e.Err)
+}
+
+// Unwrap returns the error of the API.
+func (e *PowerActionError) Unwrap() error {
+	return e.Err
+}
+
 // Client collects all methods used by the controller in the Hivelocity API.
 type Client interface {
+	// PowerOnDevice boots the device. ErrDeviceTurnedOnAlready is returned if the device is on already.
 	PowerOnDevice(ctx context.Context, deviceID int32) error
+
+	// ShutdownDevice shuts the device down gracefully. ErrDeviceShutDownAlready is returned if the device is off already.
 	ShutdownDevice(ctx context.Context, deviceID int32) error
+
+	// PowerOffDevice turns the power of the device off without shutting down the operating system.
+	// ErrDeviceShutDownAlready is returned if the device is off already.
+	PowerOffDevice(ctx context.Context, deviceID int32) error
+
+	// RebootDevice reboots the device gracefully. ErrDevicePoweredOff is returned if the device is off.
+	RebootDevice(ctx context.Context, deviceID int32) error
+
+	// PowerCycleDevice turns the power of the device off and on again. Devices which are off get turned on.
+	PowerCycleDevice(ctx context.Context, deviceID int32) error
+
+	// HardResetDevice resets the device without shutting down the operating system.
+	// ErrDevicePoweredOff is returned if the device is off.
+	HardResetDevice(ctx context.Context, deviceID int32) error
+
+	// GetDevicePowerStatus returns the power status of the device, i.e. PowerStatusOn or PowerStatusOff.
+	GetDevicePowerStatus(ctx context.Context, deviceID int32) (string, error)
+
+	// ProvisionDevice installs the operating system on the device.
+	// ErrDeviceBusy is returned if another task runs on the device, e.g. a reload. Other errors of the API are of type *APIError.
 	ProvisionDevice(ctx context.Context, deviceID int32, opts hv.BareMetalDeviceUpdate) (hv.BareMetalDevice, error)
+	// ListDevices returns all devices of the account. The devices come from an inventory which is shared by all
+	// clients with the same API key and is at most a minute old. Use GetDevice to get the current state of a device.
 	ListDevices(context.Context) ([]hv.BareMetalDevice, error)
 	ListImages(ctx context.Context, productID int32) ([]string, error)
 	ListSSHKeys(context.Context) ([]hv.SshKeyResponse, error)
@@ -58,15 +132,110 @@ type Client interface {
 	SetDeviceTags(ctx context.Context, deviceID int32, tags []string) error
 
 	GetDeviceDump(ctx context.Context, deviceID int32) (hv.DeviceDump, error)
+
+	// ListDeviceIPAssignments returns the IPv4 and IPv6 assignments which are routed to the device.
+	ListDeviceIPAssignments(ctx context.Context, deviceID int32) ([]hv.IpAssignment, error)
+
+	// ListDevicePorts returns the network ports of the device together with the IPs applied to them.
+	ListDevicePorts(ctx context.Context, deviceID int32) ([]hv.DevicePort, error)
+
+	// ListDeviceIPMISensors returns the IPMI sensors of the device with their current readings.
+	ListDeviceIPMISensors(ctx context.Context, deviceID int32) ([]IPMISensor, error)
+
+	// ListDeviceEvents returns the events of the device, e.g. reloads and power actions.
+	ListDeviceEvents(ctx context.Context, deviceID int32) ([]hv.DeviceEvent, error)
+
+	// CreateTicket opens a support ticket and returns its ID.
+	CreateTicket(ctx context.Context, subject, body string) (int32, error)
+
+	// GetTicket returns the support ticket.
+	GetTicket(ctx context.Context, ticketID int32) (hv.Ticket, error)
+
+	// ListTickets returns the support tickets of the account.
+	ListTickets(ctx context.Context) ([]hv.Ticket, error)
+
+	// BondDevicePorts starts a network task which bonds the ports of the device.
+	BondDevicePorts(ctx context.Context, deviceID int32) (hv.NetworkTaskDump, error)
+
+	// UnbondDevicePorts starts a network task which removes the bond of the device.
+	UnbondDevicePorts(ctx context.Context, deviceID int32) (hv.NetworkTaskDump, error)
+
+	// GetNetworkTask returns the network task.
+	GetNetworkTask(ctx context.Context, taskID string) (hv.NetworkTaskDump, error)
+
+	// AddIPMIWhitelistIP whitelists the IP for IPMI access to the device.
+	AddIPMIWhitelistIP(ctx context.Context, deviceID int32, ip string) error
+
+	// ClearIPMIWhitelist removes all IPs from the IPMI whitelist of the device. The Hivelocity API has no endpoint
+	// to remove IPs from the whitelist, so ErrIPMIWhitelistNotClearable is returned.
+	ClearIPMIWhitelist(ctx context.Context, deviceID int32) error
+
+	// ListNullRoutes returns all null-routed IPs of the account. The null routes come from a cache which is shared
+	// by all clients with the same API key and is at most a minute old.
+	ListNullRoutes(ctx context.Context) ([]hv.NullRoute, error)
+
+	// ListARecords returns the A records of the DNS zone.
+	ListARecords(ctx context.Context, zone string) ([]hv.ARecord, error)
+
+	// CreateARecord creates an A record in the DNS zone.
+	CreateARecord(ctx context.Context, zone string, record hv.ARecord) error
+
+	// UpdateARecord updates the A record with the name of the given record.
+	UpdateARecord(ctx context.Context, zone string, record hv.ARecord) error
+
+	// DeleteARecord deletes the A record. If the record does not exist, nil is returned.
+	DeleteARecord(ctx context.Context, zone string, name string) error
+
+	// ListAAAARecords returns the AAAA records of the DNS zone.
+	ListAAAARecords(ctx context.Context, zone string) ([]hv.AaaaRecordReturn, error)
+
+	// CreateAAAARecord creates an AAAA record in the DNS zone.
+	CreateAAAARecord(ctx context.Context, zone string, record hv.AaaaRecordCreate) error
+
+	// DeleteAAAARecord deletes the AAAA record. If the record does not exist, nil is returned.
+	DeleteAAAARecord(ctx context.Context, zone string, recordID int32) error
+
+	// ListPTRRecords returns all PTR records of the account.
+	ListPTRRecords(ctx context.Context) ([]hv.PtrRecordReturn, error)
+
+	// UpdatePTRRecord sets the name and TTL of a PTR record.
+	UpdatePTRRecord(ctx context.Context, recordID int32, update hv.PtrRecordUpdate) error
+
+	// ValidateAPIKey checks the API key with a cheap call of the API. ErrInvalidAPIKey is returned if the API rejects the key.
+	ValidateAPIKey(ctx context.Context) error
 }
 
 // Factory is the interface for creating new Client objects.
 type Factory interface {
 	NewClient(hvAPIKey string) Client
+
+	// Forget drops the state which clients of the API key share, e.g. after the API rejected the key.
+	Forget(hvAPIKey string)
 }
 
+// apiKeyStateTTL is the time after which the shared state of an API key gets dropped if no client was created for it.
+// Controllers create clients on every reconcile, which happens at least once per sync period, so that the state of
+// API keys which are still referenced by a cluster is kept.
+const apiKeyStateTTL = 30 * time.Minute
+
 // HivelocityFactory implements the Factory interface.
-type HivelocityFactory struct{}
+// Clients with the same API key share a device inventory and a rate limiter.
+// The state of an API key is dropped when it is forgotten or no longer used.
+type HivelocityFactory struct {
+	// BaseURL is the URL of the Hivelocity API, e.g. of a fake server in tests. The public API is used if it is empty.
+	BaseURL string
+
+	mu     sync.Mutex
+	shared map[string]*apiKeyState
+}
+
+// apiKeyState is shared by all clients with the same API key.
+type apiKeyState struct {
+	inventory  *deviceInventory
+	nullRoutes *nullRouteCache
+	limiter    *rateLimiter
+	lastUsed   time.Time
+}
 
 var (
 	// ErrDeviceNotFound gets returned if no matching device was found.
@@ -78,8 +247,27 @@ var (
 	// ErrDeviceTurnedOnAlready indicates that the device turned on already.
 	ErrDeviceTurnedOnAlready = fmt.Errorf("device is turned on already")
 
+	// ErrDevicePoweredOff indicates that a power action is not possible because the device is powered off.
+	ErrDevicePoweredOff = fmt.Errorf("device is powered off")
+
 	// ErrRateLimitExceeded indicates that the device turned on already.
 	ErrRateLimitExceeded = fmt.Errorf("rate limit exceeded")
+
+	// ErrNetworkTaskNotFound gets returned if the network task does not exist.
+	ErrNetworkTaskNotFound = fmt.Errorf("network task was not found")
+
+	// ErrTicketNotFound gets returned if the support ticket does not exist.
+	ErrTicketNotFound = fmt.Errorf("ticket was not found")
+
+	// ErrDNSZoneNotFound gets returned if the DNS zone does not exist.
+	ErrDNSZoneNotFound = fmt.Errorf("dns zone was not found")
+
+	// ErrIPMIWhitelistNotClearable gets returned, because the Hivelocity API cannot remove IPs from the IPMI whitelist.
+	// They have to be removed in the portal.
+	ErrIPMIWhitelistNotClearable = fmt.Errorf("the Hivelocity API cannot remove IPs from the IPMI whitelist")
+
+	// ErrInvalidAPIKey gets returned if the API rejects the API key with status code 401 or 403.
+	ErrInvalidAPIKey = fmt.Errorf("api key was rejected")
 )
 
 var _ Factory = &HivelocityFactory{}
@@ -108,22 +296,73 @@ func (lt *LoggingTransport) RoundTrip(req *http.Request) (resp *http.Response, e
 // NewClient creates new Hivelocity clients.
 func (f *HivelocityFactory) NewClient(hvAPIKey string) Client {
 	config := hv.NewConfiguration()
+	if f.BaseURL != "" {
+		config.BasePath = f.BaseURL
+	}
 	config.AddDefaultHeader("X-API-KEY", hvAPIKey)
 	config.AddDefaultHeader("CAPHV-VERSION", caphvversion.Get().String())
+	state := f.apiKeyState(hvAPIKey)
 	config.HTTPClient = &http.Client{
 		Transport: &LoggingTransport{
-			roundTripper: http.DefaultTransport,
-			log:          ctrl.Log.WithName("hivelocity-api"),
+			roundTripper: &retryTransport{
+				roundTripper: &rateLimitTransport{
+					roundTripper: &metricsTransport{
+						roundTripper: http.DefaultTransport,
+					},
+					limiter: state.limiter,
+				},
+			},
+			log: ctrl.Log.WithName("hivelocity-api"),
 		},
 	}
 	apiClient := hv.NewAPIClient(config)
-	return &realClient{
-		client: apiClient,
+	return NewTracingClient(&realClient{
+		client:     apiClient,
+		config:     config,
+		inventory:  state.inventory,
+		nullRoutes: state.nullRoutes,
+	})
+}
+
+// apiKeyState returns the state which is shared by all clients of the API key.
+// The state of API keys which were not used within apiKeyStateTTL gets dropped.
+func (f *HivelocityFactory) apiKeyState(hvAPIKey string) *apiKeyState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.shared == nil {
+		f.shared = make(map[string]*apiKeyState)
+	}
+	now := time.Now()
+	for key, state := range f.shared {
+		if now.Sub(state.lastUsed) > apiKeyStateTTL {
+			delete(f.shared, key)
+		}
 	}
+	state, ok := f.shared[hvAPIKey]
+	if !ok {
+		state = &apiKeyState{
+			inventory:  newDeviceInventory(hvAPIKey),
+			nullRoutes: newNullRouteCache(),
+			limiter:    newRateLimiter(defaultRequestsPerSecond, defaultBurst),
+		}
+		f.shared[hvAPIKey] = state
+	}
+	state.lastUsed = now
+	return state
+}
+
+// Forget implements the Factory interface.
+func (f *HivelocityFactory) Forget(hvAPIKey string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.shared, hvAPIKey)
 }
 
 type realClient struct {
-	client *hv.APIClient
+	client     *hv.APIClient
+	config     *hv.Configuration
+	inventory  *deviceInventory
+	nullRoutes *nullRouteCache
 }
 
 var _ Client = &realClient{}
@@ -132,11 +371,13 @@ func (c *realClient) GetDevice(ctx context.Context, deviceID int32) (hv.BareMeta
 	// https://developers.hivelocity.net/reference/get_bare_metal_device_id_resource
 	device, _, err := c.client.BareMetalDevicesApi.GetBareMetalDeviceIdResource(ctx, deviceID, nil) //nolint:bodyclose // Close() gets done in client
 	if err == nil {
+		c.inventory.update(device)
 		return device, nil
 	}
 	var swaggerErr hv.GenericSwaggerError
 	if errors.As(err, &swaggerErr) {
 		if strings.HasPrefix(swaggerErr.Error(), fmt.Sprint(http.StatusNotFound)) {
+			c.inventory.remove(deviceID)
 			return device, ErrDeviceNotFound
 		}
 		log := log.FromContext(ctx)
@@ -152,54 +393,126 @@ func (c *realClient) SetDeviceTags(ctx context.Context, deviceID int32, tags []s
 		Tags: tags,
 	}
 	_, _, err := c.client.DeviceApi.PutDeviceTagIdResource(ctx, deviceID, deviceTags, nil) //nolint:bodyclose // Close() gets done in client
-	return checkRateLimit(err)
+	if err != nil {
+		return checkRateLimit(err)
+	}
+	c.refreshDevice(ctx, deviceID)
+	return nil
+}
+
+// refreshDevice updates the device in the inventory after a write. The whole inventory gets listed again
+// if the device can't be read.
+func (c *realClient) refreshDevice(ctx context.Context, deviceID int32) {
+	if _, err := c.GetDevice(ctx, deviceID); err != nil && !errors.Is(err, ErrDeviceNotFound) {
+		c.inventory.invalidate()
+	}
 }
 
 func (c *realClient) PowerOnDevice(ctx context.Context, deviceID int32) error {
-	_, _, err := c.client.DeviceApi.PostPowerResource(ctx, deviceID, "boot", nil) //nolint:bodyclose // Close() gets done in client
+	err := c.powerAction(ctx, deviceID, powerActionBoot)
+	if errors.Is(err, ErrDeviceTurnedOnAlready) {
+		return ErrDeviceTurnedOnAlready
+	}
 	return err
 }
 
 func (c *realClient) ProvisionDevice(ctx context.Context, deviceID int32, opts hv.BareMetalDeviceUpdate) (hv.BareMetalDevice, error) {
 	log := log.FromContext(ctx)
-	var swaggerErr hv.GenericSwaggerError
 
 	log.Info("calling ProvisionDevice()", "DeviceID", deviceID, "hostname", opts.Hostname, "OsName", opts.OsName,
 		"script", utils.FirstN(opts.Script, 50),
 		"ForceReload", opts.ForceReload)
 
 	device, _, err := c.client.BareMetalDevicesApi.PutBareMetalDeviceIdResource(ctx, deviceID, opts, nil) //nolint:bodyclose // Close() gets done in client
-	if errors.As(err, &swaggerErr) {
-		body := string(swaggerErr.Body())
-		log.Info("ProvisionDevice() failed (PutBareMetalDeviceIdResource)", "DeviceID", deviceID, "body", body)
-		err = fmt.Errorf("%s: %w", body, swaggerErr)
-	}
 	if err == nil {
 		log.Info("ProvisionDevice() was successful (PutBareMetalDeviceIdResource)", "DeviceID", deviceID)
+		c.inventory.update(device)
+		return device, nil
 	}
-	return device, checkRateLimit(err)
+	if apiErr := parseAPIError(err); apiErr != nil {
+		log.Info("ProvisionDevice() failed (PutBareMetalDeviceIdResource)", "DeviceID", deviceID,
+			"statusCode", apiErr.StatusCode, "code", apiErr.Code, "message", apiErr.Message)
+		if apiErr.Code == APIErrorCodeNotFound {
+			return device, ErrDeviceNotFound
+		}
+		return device, apiErr
+	}
+	return device, err
 }
 
 func (c *realClient) ListDevices(ctx context.Context) ([]hv.BareMetalDevice, error) {
+	return c.inventory.list(ctx, c.listDevices)
+}
+
+func (c *realClient) listDevices(ctx context.Context) ([]hv.BareMetalDevice, error) {
+	// https://developers.hivelocity.net/reference/get_bare_metal_device_resource
 	devices, _, err := c.client.BareMetalDevicesApi.GetBareMetalDeviceResource(ctx, nil) //nolint:bodyclose // Close() gets done in client
 	return devices, checkRateLimit(err)
 }
 
 func (c *realClient) ShutdownDevice(ctx context.Context, deviceID int32) error {
-	_, _, err := c.client.DeviceApi.PostPowerResource(ctx, deviceID, "shutdown", nil) //nolint:bodyclose // Close() gets done in client
-	if err != nil {
-		swaggerErr, ok := err.(hv.GenericSwaggerError)
-		if ok {
-			body := string(swaggerErr.Body())
-			if strings.Contains(body, "Can't do this while server is powered off.") {
-				return ErrDeviceShutDownAlready
-
-			}
-			err = fmt.Errorf("%s: %w", body, err)
-		}
-		return checkRateLimit(err)
+	err := c.powerAction(ctx, deviceID, powerActionShutdown)
+	if errors.Is(err, ErrDevicePoweredOff) {
+		return ErrDeviceShutDownAlready
 	}
-	return nil
+	return err
+}
+
+func (c *realClient) PowerOffDevice(ctx context.Context, deviceID int32) error {
+	err := c.powerAction(ctx, deviceID, powerActionOff)
+	if errors.Is(err, ErrDevicePoweredOff) {
+		return ErrDeviceShutDownAlready
+	}
+	return err
+}
+
+func (c *realClient) RebootDevice(ctx context.Context, deviceID int32) error {
+	return c.powerAction(ctx, deviceID, powerActionReboot)
+}
+
+func (c *realClient) PowerCycleDevice(ctx context.Context, deviceID int32) error {
+	err := c.powerAction(ctx, deviceID, powerActionCycle)
+	if errors.Is(err, ErrDevicePoweredOff) {
+		// nothing to cycle, just turn it on
+		return c.PowerOnDevice(ctx, deviceID)
+	}
+	return err
+}
+
+func (c *realClient) HardResetDevice(ctx context.Context, deviceID int32) error {
+	return c.powerAction(ctx, deviceID, powerActionReset)
+}
+
+func (c *realClient) GetDevicePowerStatus(ctx context.Context, deviceID int32) (string, error) {
+	// https://developers.hivelocity.net/reference/get_power_resource
+	power, _, err := c.client.DeviceApi.GetPowerResource(ctx, deviceID, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return "", ErrDeviceNotFound
+	}
+	return power.PowerStatus, checkRateLimit(err)
+}
+
+// powerAction calls the power API of the device and converts the errors of the API to typed errors.
+func (c *realClient) powerAction(ctx context.Context, deviceID int32, action string) error {
+	// https://developers.hivelocity.net/reference/post_power_resource
+	_, _, err := c.client.DeviceApi.PostPowerResource(ctx, deviceID, action, nil) //nolint:bodyclose // Close() gets done in client
+	if err == nil {
+		return nil
+	}
+	apiErr := parseAPIError(err)
+	if apiErr == nil {
+		return &PowerActionError{DeviceID: deviceID, Action: action, Err: err}
+	}
+	switch apiErr.Code {
+	case APIErrorCodeNotFound:
+		return ErrDeviceNotFound
+	case APIErrorCodeRateLimited:
+		return ErrRateLimitExceeded
+	case APIErrorCodeDevicePoweredOff:
+		return ErrDevicePoweredOff
+	}
+	// errors.Is matches the typed error of the code, e.g. ErrDeviceBusy.
+	return &PowerActionError{DeviceID: deviceID, Action: action, Message: apiErr.Message, Err: apiErr}
 }
 
 func (c *realClient) ListImages(ctx context.Context, productID int32) ([]string, error) {
@@ -242,3 +555,220 @@ func (c *realClient) GetDeviceDump(ctx context.Context, deviceID int32) (hv.Devi
 	dump, _, err := c.client.DeviceApi.GetDeviceIdResource(ctx, deviceID, nil) //nolint:bodyclose // Close() gets done in client
 	return dump, err
 }
+
+func (c *realClient) ListDeviceIPAssignments(ctx context.Context, deviceID int32) ([]hv.IpAssignment, error) {
+	// https://developers.hivelocity.net/reference/get_device_ip_assignments_resource
+	assignments, _, err := c.client.DeviceApi.GetDeviceIpAssignmentsResource(ctx, deviceID, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return nil, ErrDeviceNotFound
+	}
+	return assignments, checkRateLimit(err)
+}
+
+func (c *realClient) ListDevicePorts(ctx context.Context, deviceID int32) ([]hv.DevicePort, error) {
+	// https://developers.hivelocity.net/reference/get_device_port_resource
+	ports, _, err := c.client.DeviceApi.GetDevicePortResource(ctx, deviceID, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return nil, ErrDeviceNotFound
+	}
+	return ports, checkRateLimit(err)
+}
+
+func (c *realClient) ListDeviceEvents(ctx context.Context, deviceID int32) ([]hv.DeviceEvent, error) {
+	// https://developers.hivelocity.net/reference/get_device_id_event_resource
+	events, _, err := c.client.DeviceApi.GetDeviceIdEventResource(ctx, strconv.Itoa(int(deviceID)), nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return nil, ErrDeviceNotFound
+	}
+	return events, checkRateLimit(err)
+}
+
+func (c *realClient) CreateTicket(ctx context.Context, subject, body string) (int32, error) {
+	// https://developers.hivelocity.net/reference/post_ticket_resource
+	ticket, _, err := c.client.TicketApi.PostTicketResource(ctx, hv.TicketCreate{ //nolint:bodyclose // Close() gets done in client
+		Queue:   TicketQueueSupport,
+		Subject: subject,
+		Body:    body,
+	}, nil)
+	return int32(ticket.Id), checkRateLimit(err)
+}
+
+func (c *realClient) GetTicket(ctx context.Context, ticketID int32) (hv.Ticket, error) {
+	// https://developers.hivelocity.net/reference/get_ticket_id_resource
+	ticket, _, err := c.client.TicketApi.GetTicketIdResource(ctx, ticketID, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return ticket, ErrTicketNotFound
+	}
+	return ticket, checkRateLimit(err)
+}
+
+func (c *realClient) ListTickets(ctx context.Context) ([]hv.Ticket, error) {
+	// https://developers.hivelocity.net/reference/get_ticket_resource
+	tickets, _, err := c.client.TicketApi.GetTicketResource(ctx, nil) //nolint:bodyclose // Close() gets done in client
+	return tickets, checkRateLimit(err)
+}
+
+// IsTicketResolved returns whether the support ticket was closed or resolved by Hivelocity.
+func IsTicketResolved(ticket hv.Ticket) bool {
+	return strings.EqualFold(ticket.Status, "closed") || strings.EqualFold(ticket.Status, "resolved")
+}
+
+func (c *realClient) BondDevicePorts(ctx context.Context, deviceID int32) (hv.NetworkTaskDump, error) {
+	// https://developers.hivelocity.net/reference/post_device_bond_resource
+	task, _, err := c.client.DeviceApi.PostDeviceBondResource(ctx, deviceID, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return task, ErrDeviceNotFound
+	}
+	return task, checkRateLimit(err)
+}
+
+func (c *realClient) UnbondDevicePorts(ctx context.Context, deviceID int32) (hv.NetworkTaskDump, error) {
+	// https://developers.hivelocity.net/reference/delete_device_bond_resource
+	task, _, err := c.client.DeviceApi.DeleteDeviceBondResource(ctx, deviceID, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return task, ErrDeviceNotFound
+	}
+	return task, checkRateLimit(err)
+}
+
+func (c *realClient) GetNetworkTask(ctx context.Context, taskID string) (hv.NetworkTaskDump, error) {
+	// https://developers.hivelocity.net/reference/get_network_task_id_resource
+	task, _, err := c.client.NetworkApi.GetNetworkTaskIdResource(ctx, taskID, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return task, ErrNetworkTaskNotFound
+	}
+	return task, checkRateLimit(err)
+}
+
+func (c *realClient) AddIPMIWhitelistIP(ctx context.Context, deviceID int32, ip string) error {
+	// https://developers.hivelocity.net/reference/post_device_ipmi_whitelist_resource
+	_, err := c.client.DeviceApi.PostDeviceIpmiWhitelistResource(ctx, deviceID, hv.DeviceIpmiWhitelistIp{CustIp: ip}) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return ErrDeviceNotFound
+	}
+	return checkRateLimit(err)
+}
+
+func (c *realClient) ClearIPMIWhitelist(_ context.Context, _ int32) error {
+	// The API only offers https://developers.hivelocity.net/reference/post_device_ipmi_whitelist_resource to add IPs.
+	return ErrIPMIWhitelistNotClearable
+}
+
+func (c *realClient) ListNullRoutes(ctx context.Context) ([]hv.NullRoute, error) {
+	return c.nullRoutes.list(ctx, func(ctx context.Context) ([]hv.NullRoute, error) {
+		// https://developers.hivelocity.net/reference/get_null_routes_resource
+		nullRoutes, _, err := c.client.NetworkApi.GetNullRoutesResource(ctx, nil) //nolint:bodyclose // Close() gets done in client
+		return nullRoutes, checkRateLimit(err)
+	})
+}
+
+func (c *realClient) ListARecords(ctx context.Context, zone string) ([]hv.ARecord, error) {
+	// https://developers.hivelocity.net/reference/get_a_record_resource
+	records, _, err := c.client.DomainsApi.GetARecordResource(ctx, zone, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return nil, ErrDNSZoneNotFound
+	}
+	return records, checkRateLimit(err)
+}
+
+func (c *realClient) CreateARecord(ctx context.Context, zone string, record hv.ARecord) error {
+	// https://developers.hivelocity.net/reference/post_a_record_resource
+	_, _, err := c.client.DomainsApi.PostARecordResource(ctx, zone, record, nil) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return ErrDNSZoneNotFound
+	}
+	return checkRateLimit(err)
+}
+
+func (c *realClient) UpdateARecord(ctx context.Context, zone string, record hv.ARecord) error {
+	// https://developers.hivelocity.net/reference/put_a_record_id_resource
+	_, _, err := c.client.DomainsApi.PutARecordIdResource(ctx, zone, record.Name, record, nil) //nolint:bodyclose // Close() gets done in client
+	return checkRateLimit(err)
+}
+
+func (c *realClient) DeleteARecord(ctx context.Context, zone string, name string) error {
+	// https://developers.hivelocity.net/reference/delete_a_record_id_resource
+	_, err := c.client.DomainsApi.DeleteARecordIdResource(ctx, zone, name) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return nil
+	}
+	return checkRateLimit(err)
+}
+
+func (c *realClient) ListAAAARecords(ctx context.Context, zone string) ([]hv.AaaaRecordReturn, error) {
+	domainID, err := c.domainID(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	// https://developers.hivelocity.net/reference/get_aaaa_record_resource
+	records, _, err := c.client.DomainsApi.GetAaaaRecordResource(ctx, domainID, nil) //nolint:bodyclose // Close() gets done in client
+	return records, checkRateLimit(err)
+}
+
+func (c *realClient) CreateAAAARecord(ctx context.Context, zone string, record hv.AaaaRecordCreate) error {
+	domainID, err := c.domainID(ctx, zone)
+	if err != nil {
+		return err
+	}
+	// https://developers.hivelocity.net/reference/post_aaaa_record_resource
+	_, _, err = c.client.DomainsApi.PostAaaaRecordResource(ctx, domainID, record, nil) //nolint:bodyclose // Close() gets done in client
+	return checkRateLimit(err)
+}
+
+func (c *realClient) DeleteAAAARecord(ctx context.Context, zone string, recordID int32) error {
+	domainID, err := c.domainID(ctx, zone)
+	if err != nil {
+		return err
+	}
+	// https://developers.hivelocity.net/reference/delete_aaaa_record_id_resource
+	_, err = c.client.DomainsApi.DeleteAaaaRecordIdResource(ctx, domainID, recordID) //nolint:bodyclose // Close() gets done in client
+	if isNotFound(err) {
+		return nil
+	}
+	return checkRateLimit(err)
+}
+
+// domainID returns the ID of the DNS zone. Unlike A records, AAAA records are addressed by the ID of the zone.
+func (c *realClient) domainID(ctx context.Context, zone string) (int32, error) {
+	// https://developers.hivelocity.net/reference/get_domain_resource
+	domains, _, err := c.client.DomainsApi.GetDomainResource(ctx, nil) //nolint:bodyclose // Close() gets done in client
+	if err != nil {
+		return 0, checkRateLimit(err)
+	}
+	for _, domain := range domains {
+		if strings.TrimSuffix(domain.Name, ".") == strings.TrimSuffix(zone, ".") {
+			return domain.DomainId, nil
+		}
+	}
+	return 0, ErrDNSZoneNotFound
+}
+
+func (c *realClient) ListPTRRecords(ctx context.Context) ([]hv.PtrRecordReturn, error) {
+	// https://developers.hivelocity.net/reference/get_ptr_record_resource
+	records, _, err := c.client.DomainsApi.GetPtrRecordResource(ctx, nil) //nolint:bodyclose // Close() gets done in client
+	return records, checkRateLimit(err)
+}
+
+func (c *realClient) UpdatePTRRecord(ctx context.Context, recordID int32, update hv.PtrRecordUpdate) error {
+	// https://developers.hivelocity.net/reference/put_ptr_record_id_resource
+	_, _, err := c.client.DomainsApi.PutPtrRecordIdResource(ctx, recordID, update, nil) //nolint:bodyclose // Close() gets done in client
+	return checkRateLimit(err)
+}
+
+func (c *realClient) ValidateAPIKey(ctx context.Context) error {
+	// https://developers.hivelocity.net/reference/get_basic_profile_resource
+	_, _, err := c.client.ProfileApi.GetBasicProfileResource(ctx, nil) //nolint:bodyclose // Close() gets done in client
+	if statusCode := statusCodeOf(err); statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		return ErrInvalidAPIKey
+	}
+	return checkRateLimit(err)
+}
+
+// isNotFound returns true, if the Hivelocity API responded with status code 404.
+func isNotFound(err error) bool {
+	var swaggerErr hv.GenericSwaggerError
+	if !errors.As(err, &swaggerErr) {
+		return false
+	}
+	return strings.HasPrefix(swaggerErr.Error(), fmt.Sprint(http.StatusNotFound))
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HivelocityFactory_apiKeyState(t *testing.T) {
+	factory := &HivelocityFactory{}
+
+	state := factory.apiKeyState("key-1")
+	require.Same(t, state, factory.apiKeyState("key-1"))
+	require.NotSame(t, state, factory.apiKeyState("key-2"))
+
+	// forgotten keys get a new state
+	factory.Forget("key-1")
+	require.NotContains(t, factory.shared, "key-1")
+	state = factory.apiKeyState("key-1")
+
+	// the state of keys which are no longer used gets dropped
+	factory.shared["key-2"].lastUsed = time.Now().Add(-apiKeyStateTTL - time.Minute)
+	require.Same(t, state, factory.apiKeyState("key-1"))
+	require.NotContains(t, factory.shared, "key-2")
+
+	// forgetting unknown keys does nothing
+	factory.Forget("unknown")
+	require.Len(t, factory.shared, 1)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+// inventoryRefreshInterval is the maximum age of the device inventory. Older inventories get listed again.
+const inventoryRefreshInterval = time.Minute
+
+// deviceInventory caches the devices of one API key, so that not every caller of ListDevices lists all devices
+// of the account. Writes to a device refresh the cached device.
+type deviceInventory struct {
+	// name identifies the inventory in metrics without revealing the API key.
+	name string
+
+	mu          sync.Mutex
+	devices     map[int32]hv.BareMetalDevice
+	lastRefresh time.Time
+	now         func() time.Time
+}
+
+func newDeviceInventory(hvAPIKey string) *deviceInventory {
+	sum := sha256.Sum256([]byte(hvAPIKey))
+	return &deviceInventory{
+		name: hex.EncodeToString(sum[:])[:8],
+		now:  time.Now,
+	}
+}
+
+// list returns the cached devices sorted by ID. The devices get listed with listFunc if the inventory is older
+// than inventoryRefreshInterval.
+func (i *deviceInventory) list(ctx context.Context, listFunc func(context.Context) ([]hv.BareMetalDevice, error)) (
+	[]hv.BareMetalDevice, error,
+) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.devices == nil || i.now().Sub(i.lastRefresh) > inventoryRefreshInterval {
+		devices, err := listFunc(ctx)
+		if err != nil {
+			return nil, err
+		}
+		i.devices = make(map[int32]hv.BareMetalDevice, len(devices))
+		for _, device := range devices {
+			i.devices[device.DeviceId] = device
+		}
+		i.lastRefresh = i.now()
+		metrics.RecordDeviceInventoryRefresh(i.name, i.lastRefresh, len(devices))
+	}
+
+	devices := make([]hv.BareMetalDevice, 0, len(i.devices))
+	for _, device := range i.devices {
+		// callers may append to the tags, which must not change the cache.
+		device.Tags = append([]string(nil), device.Tags...)
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(a, b int) bool {
+		return devices[a].DeviceId < devices[b].DeviceId
+	})
+	return devices, nil
+}
+
+// update replaces a cached device, e.g. after it was read with GetDevice.
+func (i *deviceInventory) update(device hv.BareMetalDevice) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.devices == nil {
+		return
+	}
+	device.Tags = append([]string(nil), device.Tags...)
+	i.devices[device.DeviceId] = device
+}
+
+// remove removes a device which does not exist anymore.
+func (i *deviceInventory) remove(deviceID int32) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.devices, deviceID)
+}
+
+// invalidate makes the next list call list the devices again.
+func (i *deviceInventory) invalidate() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.devices = nil
+}
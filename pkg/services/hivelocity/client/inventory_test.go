@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_deviceInventory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	inventory := newDeviceInventory("dummy-key")
+	inventory.now = func() time.Time { return now }
+
+	calls := 0
+	listFunc := func(context.Context) ([]hv.BareMetalDevice, error) {
+		calls++
+		return []hv.BareMetalDevice{
+			{DeviceId: 2, Tags: []string{"caphv-use=allow"}},
+			{DeviceId: 1},
+		}, nil
+	}
+
+	devices, err := inventory.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Len(t, devices, 2)
+	require.Equal(t, int32(1), devices[0].DeviceId)
+
+	// appending to the returned tags does not change the cache
+	devices[1].Tags = append(devices[1].Tags, "caphv-cluster-name=foo")
+	devices, err = inventory.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Equal(t, []string{"caphv-use=allow"}, devices[1].Tags)
+
+	// writes refresh single devices
+	inventory.update(hv.BareMetalDevice{DeviceId: 2, Tags: []string{"caphv-use=allow", "caphv-cluster-name=foo"}})
+	inventory.remove(1)
+	devices, err = inventory.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Len(t, devices, 1)
+	require.Equal(t, []string{"caphv-use=allow", "caphv-cluster-name=foo"}, devices[0].Tags)
+
+	// the devices get listed again once the inventory is too old
+	now = now.Add(2 * inventoryRefreshInterval)
+	devices, err = inventory.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	require.Len(t, devices, 2)
+
+	inventory.invalidate()
+	_, err = inventory.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// IPMISensor is an IPMI sensor of a device. Unlike hv.IpmiSensor, it keeps whether the API reported the status of
+// the sensor at all. Status is nil for sensors without status, e.g. sensors which only report a reading.
+type IPMISensor struct {
+	SensorID string  `json:"sensorId,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Group    string  `json:"group,omitempty"`
+	Units    string  `json:"units,omitempty"`
+	Reading  float32 `json:"reading,omitempty"`
+	Status   *bool   `json:"status,omitempty"`
+}
+
+// IsFailing returns true if the sensor explicitly reports a failure.
+func (s IPMISensor) IsFailing() bool {
+	return s.Status != nil && !*s.Status
+}
+
+// ListDeviceIPMISensors reads the sensors without the generated client, because it decodes a missing status as false.
+func (c *realClient) ListDeviceIPMISensors(ctx context.Context, deviceID int32) ([]IPMISensor, error) {
+	// https://developers.hivelocity.net/reference/get_ipmi_info_id_resource
+	var info struct {
+		Sensors []IPMISensor `json:"sensors"`
+	}
+	err := c.getJSON(ctx, fmt.Sprintf("/device/%d/ipmi", deviceID), &info)
+	switch statusCodeOf(err) {
+	case http.StatusNotFound:
+		return nil, ErrDeviceNotFound
+	case http.StatusTooManyRequests:
+		return nil, ErrRateLimitExceeded
+	}
+	return info.Sensors, err
+}
+
+// getJSON decodes the response of a GET request into v. Error responses are returned as *APIError.
+func (c *realClient) getJSON(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.BasePath+path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range c.config.DefaultHeader {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.config.UserAgent)
+
+	httpClient := c.config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return newAPIError(resp.StatusCode, body, errors.New(resp.Status))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
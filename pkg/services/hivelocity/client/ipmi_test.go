@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ListDeviceIPMISensors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/device/42/ipmi", r.URL.Path)
+		require.Equal(t, "my-key", r.Header.Get("X-API-KEY"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sensors": [
+			{"sensorId": "1", "name": "CPU Temp", "units": "degrees C", "reading": 98.5, "status": false},
+			{"sensorId": "2", "name": "FAN1", "units": "RPM", "reading": 4200, "status": true},
+			{"sensorId": "3", "name": "Inlet Temp", "units": "degrees C", "reading": 24}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	config := hv.NewConfiguration()
+	config.BasePath = server.URL
+	config.AddDefaultHeader("X-API-KEY", "my-key")
+	client := &realClient{client: hv.NewAPIClient(config), config: config}
+
+	sensors, err := client.ListDeviceIPMISensors(context.Background(), 42)
+	require.NoError(t, err)
+	require.Len(t, sensors, 3)
+	require.True(t, sensors[0].IsFailing())
+	require.False(t, sensors[1].IsFailing())
+	// the status of the third sensor is missing, which is no failure
+	require.Nil(t, sensors[2].Status)
+	require.False(t, sensors[2].IsFailing())
+}
+
+func Test_ListDeviceIPMISensors_errors(t *testing.T) {
+	client := newTestServerClient(t, http.StatusNotFound, "not-found.json")
+	_, err := client.ListDeviceIPMISensors(context.Background(), 42)
+	require.ErrorIs(t, err, ErrDeviceNotFound)
+
+	client = newTestServerClient(t, http.StatusTooManyRequests, "rate-limited.json")
+	_, err = client.ListDeviceIPMISensors(context.Background(), 42)
+	require.ErrorIs(t, err, ErrRateLimitExceeded)
+
+	client = newTestServerClient(t, http.StatusBadGateway, "bad-gateway.html")
+	_, err = client.ListDeviceIPMISensors(context.Background(), 42)
+	require.Equal(t, ErrorCategoryTransient, CategoryOf(err))
+}
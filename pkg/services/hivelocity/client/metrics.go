@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// apiBasePath is the path prefix of all endpoints of the Hivelocity API.
+const apiBasePath = "/api/v2"
+
+// metricsTransport records Prometheus metrics for each request to the Hivelocity API.
+type metricsTransport struct {
+	roundTripper http.RoundTripper
+}
+
+// RoundTrip sends the request and records its status code and latency.
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation := operationOf(req)
+
+	metrics.HivelocityAPIRequestsInFlight.Inc()
+	defer metrics.HivelocityAPIRequestsInFlight.Dec()
+
+	start := time.Now()
+	resp, err := t.roundTripper.RoundTrip(req)
+	statusCode := 0
+	if err == nil {
+		statusCode = resp.StatusCode
+	}
+	metrics.RecordHivelocityAPIRequest(operation, statusCode, time.Since(start))
+	return resp, err
+}
+
+// operationOf returns the method and the path of the request without variable segments, for example
+// "GET /device/{id}/tags". Every segment which is not a constant segment of an API route is replaced, e.g. IDs,
+// zone and record names, so that the number of label values of the metrics is bounded.
+func operationOf(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, apiBasePath)
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && !apiPathSegments.Has(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
+
+// apiPathSegments are the constant segments of the routes of the Hivelocity API.
+var apiPathSegments = sets.New(
+	"a-record", "aaaa-record", "account", "address", "all", "apply-coupon", "attachiso", "available-sizes",
+	"available-volume-sizes", "bandwidth", "bare-metal-devices", "basic", "batch", "billing-info", "bond",
+	"cancellation", "clear", "combine", "console", "contact", "controlled-client", "credit", "deploy",
+	"detachiso", "details", "device", "domains", "events", "ignition", "image", "in-progress", "initial-creds",
+	"initial-password", "inventory", "invoice", "ip", "ipmi", "ips", "iso", "locations", "login-data",
+	"manageable", "managed-requirements", "metrics", "mx-record", "nat", "network", "null", "null-route",
+	"operating-systems", "options", "order", "order-groups", "password", "pdf-download", "permission", "port",
+	"ports", "power", "preview-ignition", "product", "profile", "ptr", "reload", "reply", "search",
+	"self-metadata", "service", "services", "snapshot", "snapshotSchedule", "split", "ssh_key", "status", "tags",
+	"tags-order", "thresholds", "tickets", "token", "total", "trigger", "types", "unnull", "unpaid", "user",
+	"valid-login", "validate-coupon", "vlan", "volume", "vps", "webhooks", "whitelist",
+)
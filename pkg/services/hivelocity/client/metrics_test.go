@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_operationOf(t *testing.T) {
+	for _, tc := range []struct {
+		method   string
+		url      string
+		expected string
+	}{
+		{http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/", "GET /bare-metal-devices/"},
+		{http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/123", "GET /bare-metal-devices/{id}"},
+		{http.MethodPost, "https://core.hivelocity.net/api/v2/device/123/power?action=boot", "POST /device/{id}/power"},
+		{http.MethodPut, "https://core.hivelocity.net/api/v2/device/123/tags", "PUT /device/{id}/tags"},
+		{http.MethodGet, "https://core.hivelocity.net/api/v2/device/123/ipmi/thresholds", "GET /device/{id}/ipmi/thresholds"},
+		// variable segments which are not numeric are replaced as well
+		{http.MethodPut, "https://core.hivelocity.net/api/v2/domains/example.com/a-record/my-record", "PUT /domains/{id}/a-record/{id}"},
+		{http.MethodGet, "https://core.hivelocity.net/api/v2/network/null/203.0.113.5", "GET /network/null/{id}"},
+		{http.MethodGet, "https://core.hivelocity.net/api/v2/unknown/route", "GET /{id}/{id}"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.url, http.NoBody)
+		require.Equal(t, tc.expected, operationOf(req), tc.url)
+	}
+}
+
+func Test_metricsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	const operation = "GET /device/{id}/events"
+	requests := metrics.HivelocityAPIRequests.WithLabelValues(operation, "429")
+	rateLimited := metrics.HivelocityAPIRateLimited.WithLabelValues(operation)
+	before := valueOf(t, requests)
+	rateLimitedBefore := valueOf(t, rateLimited)
+
+	transport := &metricsTransport{roundTripper: http.DefaultTransport}
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/device/1/events", http.NoBody)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Equal(t, before+1, valueOf(t, requests))
+	require.Equal(t, rateLimitedBefore+1, valueOf(t, rateLimited))
+	require.Equal(t, float64(0), valueOf(t, metrics.HivelocityAPIRequestsInFlight))
+}
+
+func valueOf(t *testing.T, m prometheus.Metric) float64 {
+	t.Helper()
+	var out dto.Metric
+	require.NoError(t, m.Write(&out))
+	if out.Counter != nil {
+		return out.Counter.GetValue()
+	}
+	return out.Gauge.GetValue()
+}
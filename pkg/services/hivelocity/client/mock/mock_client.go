@@ -25,6 +25,7 @@ import (
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
 	hv "github.com/hivelocity/hivelocity-client-go/client"
 	"golang.org/x/exp/maps"
+	"k8s.io/utils/ptr"
 )
 
 // DefaultCPUCores defines the default cpu cores for Hivelocity machines' capacities.
@@ -136,18 +137,37 @@ var WithPrimaryIPDevice = hv.BareMetalDevice{
 }
 
 type mockedHVClient struct {
-	store *deviceStore
+	store  *deviceStore
+	apiKey string
+}
+
+// DefaultDeviceEvents are the events of the device with FreeDeviceID, oldest first.
+var DefaultDeviceEvents = []hv.DeviceEvent{
+	{Action: "Device reload", Time: 1700000000},
+	{Action: "Power on", Time: 1700000600},
+}
+
+// DefaultIPMISensors are the IPMI sensors of every device. None of them is failing; the last one reports no status.
+var DefaultIPMISensors = []hvclient.IPMISensor{
+	{SensorID: "1", Name: "CPU Temp", Group: "Temperature", Units: "degrees C", Reading: 45, Status: ptr.To(true)},
+	{SensorID: "2", Name: "FAN1", Group: "Fan", Units: "RPM", Reading: 4200, Status: ptr.To(true)},
+	{SensorID: "3", Name: "PS1 Status", Group: "Power Supply", Units: "discrete", Reading: 1, Status: ptr.To(true)},
+	{SensorID: "4", Name: "Inlet Temp", Group: "Temperature", Units: "degrees C", Reading: 24},
 }
 
 var _ hvclient.Client = &mockedHVClient{}
 
 // NewClient gives reference to the mock client using the in memory store.
-func (f *mockedHVClientFactory) NewClient(_ string) hvclient.Client {
+func (f *mockedHVClientFactory) NewClient(hvAPIKey string) hvclient.Client {
 	return &mockedHVClient{
-		store: f.store,
+		store:  f.store,
+		apiKey: hvAPIKey,
 	}
 }
 
+// Forget does nothing, because all mocked clients share the in memory store.
+func (f *mockedHVClientFactory) Forget(_ string) {}
+
 type mockedHVClientFactory struct {
 	store *deviceStore
 }
@@ -170,6 +190,24 @@ func NewMockedHVClientFactory() hvclient.Factory {
 	for i := range devices {
 		store.idMap[devices[i].DeviceId] = devices[i]
 	}
+	store.aRecords = map[string]map[string]hv.ARecord{
+		DNSZone: {},
+	}
+	store.aaaaRecords = map[string]map[int32]hv.AaaaRecordReturn{
+		DNSZone: {},
+	}
+	store.ipAssignments = make(map[int32][]hv.IpAssignment)
+	store.ports = make(map[int32][]hv.DevicePort)
+	store.ipmiWhitelist = make(map[int32][]string)
+	store.ipmiSensors = make(map[int32][]hvclient.IPMISensor)
+	store.networkTasks = make(map[string]hv.NetworkTaskDump)
+	store.tickets = make(map[int32]hv.Ticket)
+	store.deviceEvents = map[int32][]hv.DeviceEvent{
+		FreeDeviceID: DefaultDeviceEvents,
+	}
+	store.ptrRecords = map[int32]hv.PtrRecordReturn{
+		DefaultPTRRecord.Id: DefaultPTRRecord,
+	}
 	return &mockedHVClientFactory{store: &store}
 }
 
@@ -177,7 +215,34 @@ var _ = hvclient.Factory(&mockedHVClientFactory{})
 
 // deviceStore is an in memory store for the state for the mocked client.
 type deviceStore struct {
-	idMap map[int32]hv.BareMetalDevice
+	idMap         map[int32]hv.BareMetalDevice
+	ipAssignments map[int32][]hv.IpAssignment
+	ports         map[int32][]hv.DevicePort
+	ipmiWhitelist map[int32][]string
+	ipmiSensors   map[int32][]hvclient.IPMISensor
+	deviceEvents  map[int32][]hv.DeviceEvent
+	tickets       map[int32]hv.Ticket
+	networkTasks  map[string]hv.NetworkTaskDump
+	nullRoutes    []hv.NullRoute
+	aRecords      map[string]map[string]hv.ARecord
+	aaaaRecords   map[string]map[int32]hv.AaaaRecordReturn
+	lastRecordID  int32
+	ptrRecords    map[int32]hv.PtrRecordReturn
+}
+
+// InvalidAPIKey is the API key which the mocked client rejects.
+const InvalidAPIKey = "invalid-api-key"
+
+// DNSZone is the DNS zone which exists in the mocked client.
+const DNSZone = "example.com"
+
+// DefaultPTRRecord is a PTR record which exists in the mocked client.
+var DefaultPTRRecord = hv.PtrRecordReturn{
+	Id:      1,
+	Address: "127.0.0.1",
+	Name:    "localhost",
+	Type_:   "PTR",
+	Ttl:     3600,
 }
 
 var defaultSSHKey = hv.SshKeyResponse{
@@ -235,6 +300,62 @@ func (c *mockedHVClient) PowerOnDevice(_ context.Context, deviceID int32) error
 	return nil
 }
 
+func (c *mockedHVClient) PowerOffDevice(_ context.Context, deviceID int32) error {
+	device, found := c.store.idMap[deviceID]
+	if !found {
+		return fmt.Errorf("[PowerOffDevice] deviceID %d: %w", deviceID, hvclient.ErrDeviceNotFound)
+	}
+	if device.PowerStatus == hvclient.PowerStatusOff {
+		return hvclient.ErrDeviceShutDownAlready
+	}
+
+	device.PowerStatus = hvclient.PowerStatusOff
+	c.store.idMap[deviceID] = device
+	return nil
+}
+
+func (c *mockedHVClient) RebootDevice(_ context.Context, deviceID int32) error {
+	device, found := c.store.idMap[deviceID]
+	if !found {
+		return fmt.Errorf("[RebootDevice] deviceID %d: %w", deviceID, hvclient.ErrDeviceNotFound)
+	}
+	if device.PowerStatus == hvclient.PowerStatusOff {
+		return hvclient.ErrDevicePoweredOff
+	}
+	return nil
+}
+
+// PowerCycleDevice turns the device on. Devices which are turned off get booted as well.
+func (c *mockedHVClient) PowerCycleDevice(_ context.Context, deviceID int32) error {
+	device, found := c.store.idMap[deviceID]
+	if !found {
+		return fmt.Errorf("[PowerCycleDevice] deviceID %d: %w", deviceID, hvclient.ErrDeviceNotFound)
+	}
+
+	device.PowerStatus = hvclient.PowerStatusOn
+	c.store.idMap[deviceID] = device
+	return nil
+}
+
+func (c *mockedHVClient) HardResetDevice(_ context.Context, deviceID int32) error {
+	device, found := c.store.idMap[deviceID]
+	if !found {
+		return fmt.Errorf("[HardResetDevice] deviceID %d: %w", deviceID, hvclient.ErrDeviceNotFound)
+	}
+	if device.PowerStatus == hvclient.PowerStatusOff {
+		return hvclient.ErrDevicePoweredOff
+	}
+	return nil
+}
+
+func (c *mockedHVClient) GetDevicePowerStatus(_ context.Context, deviceID int32) (string, error) {
+	device, found := c.store.idMap[deviceID]
+	if !found {
+		return "", fmt.Errorf("[GetDevicePowerStatus] deviceID %d: %w", deviceID, hvclient.ErrDeviceNotFound)
+	}
+	return device.PowerStatus, nil
+}
+
 func (c *mockedHVClient) ListSSHKeys(_ context.Context) ([]hv.SshKeyResponse, error) {
 	return []hv.SshKeyResponse{defaultSSHKey}, nil
 }
@@ -287,3 +408,238 @@ func (c *mockedHVClient) GetDeviceDump(ctx context.Context, deviceID int32) (hv.
 		SpsStatus:          "",
 	}, nil
 }
+
+func (c *mockedHVClient) ListDeviceIPAssignments(_ context.Context, deviceID int32) ([]hv.IpAssignment, error) {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return nil, hvclient.ErrDeviceNotFound
+	}
+	return c.store.ipAssignments[deviceID], nil
+}
+
+func (c *mockedHVClient) ListDevicePorts(_ context.Context, deviceID int32) ([]hv.DevicePort, error) {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return nil, hvclient.ErrDeviceNotFound
+	}
+	return c.store.ports[deviceID], nil
+}
+
+// BondDevicePorts replaces the ports of the device with a bond. The task succeeds immediately.
+func (c *mockedHVClient) BondDevicePorts(_ context.Context, deviceID int32) (hv.NetworkTaskDump, error) {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return hv.NetworkTaskDump{}, hvclient.ErrDeviceNotFound
+	}
+	c.store.ports[deviceID] = []hv.DevicePort{{DeviceId: deviceID, PortId: deviceID, Type_: hvclient.PortTypeBond}}
+	return c.newNetworkTask(deviceID), nil
+}
+
+// UnbondDevicePorts removes the ports of the device. The task succeeds immediately.
+func (c *mockedHVClient) UnbondDevicePorts(_ context.Context, deviceID int32) (hv.NetworkTaskDump, error) {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return hv.NetworkTaskDump{}, hvclient.ErrDeviceNotFound
+	}
+	delete(c.store.ports, deviceID)
+	return c.newNetworkTask(deviceID), nil
+}
+
+func (c *mockedHVClient) GetNetworkTask(_ context.Context, taskID string) (hv.NetworkTaskDump, error) {
+	task, ok := c.store.networkTasks[taskID]
+	if !ok {
+		return hv.NetworkTaskDump{}, hvclient.ErrNetworkTaskNotFound
+	}
+	return task, nil
+}
+
+func (c *mockedHVClient) newNetworkTask(deviceID int32) hv.NetworkTaskDump {
+	task := hv.NetworkTaskDump{
+		TaskId:   fmt.Sprintf("task-%d", len(c.store.networkTasks)+1),
+		DeviceId: deviceID,
+		Result:   hvclient.NetworkTaskResultSuccess,
+	}
+	c.store.networkTasks[task.TaskId] = task
+	return task
+}
+
+func (c *mockedHVClient) AddIPMIWhitelistIP(_ context.Context, deviceID int32, ip string) error {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return hvclient.ErrDeviceNotFound
+	}
+	c.store.ipmiWhitelist[deviceID] = append(c.store.ipmiWhitelist[deviceID], ip)
+	return nil
+}
+
+// ClearIPMIWhitelist fails like the real client, because the API cannot remove IPs from the whitelist.
+func (c *mockedHVClient) ClearIPMIWhitelist(_ context.Context, deviceID int32) error {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return hvclient.ErrDeviceNotFound
+	}
+	return hvclient.ErrIPMIWhitelistNotClearable
+}
+
+func (c *mockedHVClient) ListDeviceEvents(_ context.Context, deviceID int32) ([]hv.DeviceEvent, error) {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return nil, hvclient.ErrDeviceNotFound
+	}
+	return c.store.deviceEvents[deviceID], nil
+}
+
+// CreateTicket opens a ticket with status "Open".
+func (c *mockedHVClient) CreateTicket(_ context.Context, subject, body string) (int32, error) {
+	id := int32(len(c.store.tickets) + 1)
+	c.store.tickets[id] = hv.Ticket{
+		Id:      float32(id),
+		Queue:   hvclient.TicketQueueSupport,
+		Subject: subject,
+		Body:    body,
+		Status:  "Open",
+	}
+	return id, nil
+}
+
+// GetTicket returns the ticket. Use SetTicketStatus to change its status.
+func (c *mockedHVClient) GetTicket(_ context.Context, ticketID int32) (hv.Ticket, error) {
+	ticket, ok := c.store.tickets[ticketID]
+	if !ok {
+		return hv.Ticket{}, hvclient.ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+// ListTickets returns all tickets ordered by ID.
+func (c *mockedHVClient) ListTickets(_ context.Context) ([]hv.Ticket, error) {
+	tickets := make([]hv.Ticket, 0, len(c.store.tickets))
+	for id := int32(1); id <= int32(len(c.store.tickets)); id++ {
+		tickets = append(tickets, c.store.tickets[id])
+	}
+	return tickets, nil
+}
+
+// SetTicketStatus sets the status of a ticket, e.g. to simulate that Hivelocity resolved it.
+func SetTicketStatus(client hvclient.Client, ticketID int32, status string) {
+	c, ok := client.(*mockedHVClient)
+	if !ok {
+		panic(fmt.Sprintf("expected mocked client, got %T", client))
+	}
+	ticket := c.store.tickets[ticketID]
+	ticket.Status = status
+	c.store.tickets[ticketID] = ticket
+}
+
+// SetIPMISensors replaces the IPMI sensors of a device, e.g. to simulate a hardware failure.
+func SetIPMISensors(client hvclient.Client, deviceID int32, sensors []hvclient.IPMISensor) {
+	c, ok := client.(*mockedHVClient)
+	if !ok {
+		panic(fmt.Sprintf("expected mocked client, got %T", client))
+	}
+	c.store.ipmiSensors[deviceID] = sensors
+}
+
+// ListDeviceIPMISensors returns the sensors set with SetIPMISensors, or DefaultIPMISensors.
+func (c *mockedHVClient) ListDeviceIPMISensors(_ context.Context, deviceID int32) ([]hvclient.IPMISensor, error) {
+	if _, ok := c.store.idMap[deviceID]; !ok {
+		return nil, hvclient.ErrDeviceNotFound
+	}
+	if sensors, ok := c.store.ipmiSensors[deviceID]; ok {
+		return sensors, nil
+	}
+	return DefaultIPMISensors, nil
+}
+
+func (c *mockedHVClient) ListNullRoutes(_ context.Context) ([]hv.NullRoute, error) {
+	return c.store.nullRoutes, nil
+}
+
+func (c *mockedHVClient) ListARecords(_ context.Context, zone string) ([]hv.ARecord, error) {
+	records, ok := c.store.aRecords[zone]
+	if !ok {
+		return nil, hvclient.ErrDNSZoneNotFound
+	}
+	return maps.Values(records), nil
+}
+
+func (c *mockedHVClient) CreateARecord(_ context.Context, zone string, record hv.ARecord) error {
+	records, ok := c.store.aRecords[zone]
+	if !ok {
+		return hvclient.ErrDNSZoneNotFound
+	}
+	if _, found := records[record.Name]; found {
+		return fmt.Errorf("[CreateARecord] record %q exists already in zone %q", record.Name, zone)
+	}
+	records[record.Name] = record
+	return nil
+}
+
+func (c *mockedHVClient) UpdateARecord(_ context.Context, zone string, record hv.ARecord) error {
+	records, ok := c.store.aRecords[zone]
+	if !ok {
+		return hvclient.ErrDNSZoneNotFound
+	}
+	if _, found := records[record.Name]; !found {
+		return fmt.Errorf("[UpdateARecord] record %q not found in zone %q", record.Name, zone)
+	}
+	records[record.Name] = record
+	return nil
+}
+
+func (c *mockedHVClient) DeleteARecord(_ context.Context, zone string, name string) error {
+	if records, ok := c.store.aRecords[zone]; ok {
+		delete(records, name)
+	}
+	return nil
+}
+
+func (c *mockedHVClient) ListAAAARecords(_ context.Context, zone string) ([]hv.AaaaRecordReturn, error) {
+	records, ok := c.store.aaaaRecords[zone]
+	if !ok {
+		return nil, hvclient.ErrDNSZoneNotFound
+	}
+	return maps.Values(records), nil
+}
+
+func (c *mockedHVClient) CreateAAAARecord(_ context.Context, zone string, record hv.AaaaRecordCreate) error {
+	records, ok := c.store.aaaaRecords[zone]
+	if !ok {
+		return hvclient.ErrDNSZoneNotFound
+	}
+	c.store.lastRecordID++
+	records[c.store.lastRecordID] = hv.AaaaRecordReturn{
+		Id:      c.store.lastRecordID,
+		Address: record.Address,
+		Name:    record.Name,
+		Ttl:     record.Ttl,
+		Type_:   "AAAA",
+	}
+	return nil
+}
+
+func (c *mockedHVClient) DeleteAAAARecord(_ context.Context, zone string, recordID int32) error {
+	records, ok := c.store.aaaaRecords[zone]
+	if !ok {
+		return hvclient.ErrDNSZoneNotFound
+	}
+	delete(records, recordID)
+	return nil
+}
+
+func (c *mockedHVClient) ListPTRRecords(_ context.Context) ([]hv.PtrRecordReturn, error) {
+	return maps.Values(c.store.ptrRecords), nil
+}
+
+func (c *mockedHVClient) UpdatePTRRecord(_ context.Context, recordID int32, update hv.PtrRecordUpdate) error {
+	record, ok := c.store.ptrRecords[recordID]
+	if !ok {
+		return fmt.Errorf("[UpdatePTRRecord] PTR record %d not found", recordID)
+	}
+	record.Name = update.Name
+	if update.Ttl != 0 {
+		record.Ttl = update.Ttl
+	}
+	c.store.ptrRecords[recordID] = record
+	return nil
+}
+
+func (c *mockedHVClient) ValidateAPIKey(_ context.Context) error {
+	if c.apiKey == InvalidAPIKey {
+		return hvclient.ErrInvalidAPIKey
+	}
+	return nil
+}
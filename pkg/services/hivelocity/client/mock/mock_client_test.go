@@ -85,3 +85,27 @@ func Test_ProvisionDevice(t *testing.T) {
 	require.NoError(t, err)
 	require.ElementsMatch(t, []string{"dummyTag"}, device.Tags)
 }
+
+func Test_PowerActions(t *testing.T) {
+	client := NewMockedHVClientFactory().NewClient("dummy-key")
+	ctx := context.Background()
+
+	status, err := client.GetDevicePowerStatus(ctx, FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, hvclient.PowerStatusOn, status)
+
+	require.NoError(t, client.RebootDevice(ctx, FreeDeviceID))
+	require.NoError(t, client.HardResetDevice(ctx, FreeDeviceID))
+
+	require.NoError(t, client.PowerOffDevice(ctx, FreeDeviceID))
+	require.ErrorIs(t, client.PowerOffDevice(ctx, FreeDeviceID), hvclient.ErrDeviceShutDownAlready)
+	require.ErrorIs(t, client.RebootDevice(ctx, FreeDeviceID), hvclient.ErrDevicePoweredOff)
+	require.ErrorIs(t, client.HardResetDevice(ctx, FreeDeviceID), hvclient.ErrDevicePoweredOff)
+
+	require.NoError(t, client.PowerCycleDevice(ctx, FreeDeviceID))
+	status, err = client.GetDevicePowerStatus(ctx, FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, hvclient.PowerStatusOn, status)
+
+	require.ErrorIs(t, client.PowerCycleDevice(ctx, -1), hvclient.ErrDeviceNotFound)
+}
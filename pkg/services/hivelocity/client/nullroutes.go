@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+// nullRouteRefreshInterval is the maximum age of the cached null routes. Older null routes get listed again.
+const nullRouteRefreshInterval = time.Minute
+
+// nullRouteCache caches the null routes of one API key, so that the machines of the account do not all list
+// the null routes of the whole account.
+type nullRouteCache struct {
+	mu          sync.Mutex
+	routes      []hv.NullRoute
+	lastRefresh time.Time
+	now         func() time.Time
+}
+
+func newNullRouteCache() *nullRouteCache {
+	return &nullRouteCache{now: time.Now}
+}
+
+// list returns the cached null routes. They get listed with listFunc if the cache is older than nullRouteRefreshInterval.
+func (c *nullRouteCache) list(ctx context.Context, listFunc func(context.Context) ([]hv.NullRoute, error)) (
+	[]hv.NullRoute, error,
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lastRefresh.IsZero() || c.now().Sub(c.lastRefresh) > nullRouteRefreshInterval {
+		routes, err := listFunc(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.routes = routes
+		c.lastRefresh = c.now()
+	}
+	return append([]hv.NullRoute(nil), c.routes...), nil
+}
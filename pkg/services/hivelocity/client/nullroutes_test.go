@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nullRouteCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := newNullRouteCache()
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	routes := []hv.NullRoute{{Ip: "192.0.2.10"}}
+	listFunc := func(context.Context) ([]hv.NullRoute, error) {
+		calls++
+		return routes, nil
+	}
+
+	got, err := cache.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, routes, got)
+
+	// cached within the refresh interval
+	_, err = cache.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	// listed again after the interval. Errors are not cached.
+	now = now.Add(nullRouteRefreshInterval + time.Second)
+	_, err = cache.list(ctx, func(context.Context) ([]hv.NullRoute, error) { return nil, errors.New("failed") })
+	require.Error(t, err)
+	_, err = cache.list(ctx, listFunc)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
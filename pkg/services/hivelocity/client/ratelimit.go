@@ -0,0 +1,247 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRequestsPerSecond is the rate at which the rate limiter allows requests to the Hivelocity API.
+	defaultRequestsPerSecond = 2
+
+	// defaultBurst is the number of requests which may be sent at once.
+	defaultBurst = 10
+
+	// defaultRetryAfter is used if the API responds with 429 without a Retry-After header.
+	defaultRetryAfter = 10 * time.Second
+)
+
+// requestPriority defines the order in which waiting requests are sent. Lower values are sent first.
+type requestPriority int
+
+const (
+	// priorityHigh is used for requests which free or stop resources, like delete and shutdown.
+	priorityHigh requestPriority = iota
+
+	// priorityNormal is used for all requests which are neither high nor low priority.
+	priorityNormal
+
+	// priorityLow is used for requests which list resources.
+	priorityLow
+)
+
+// priorityOf returns the priority of the request to the Hivelocity API.
+func priorityOf(req *http.Request) requestPriority {
+	switch req.Method {
+	case http.MethodDelete:
+		return priorityHigh
+	case http.MethodPost:
+		if strings.HasSuffix(req.URL.Path, "/power") {
+			action := req.URL.Query().Get("action")
+			if action == powerActionShutdown || action == powerActionOff {
+				return priorityHigh
+			}
+		}
+	case http.MethodGet:
+		// collections like "/bare-metal-devices/" end with a slash.
+		if strings.HasSuffix(req.URL.Path, "/") {
+			return priorityLow
+		}
+	}
+	return priorityNormal
+}
+
+// waiter is a request which waits for a token of the rate limiter.
+type waiter struct {
+	priority requestPriority
+	seq      uint64
+	ready    chan struct{}
+}
+
+// rateLimiter is a token bucket which is shared by all clients with the same API key. Waiting requests get their
+// token by priority. The API can pause all requests with the Retry-After and X-RateLimit-* headers.
+type rateLimiter struct {
+	mu           sync.Mutex
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	waiters      []*waiter
+	seq          uint64
+	timer        *time.Timer
+	now          func() time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// wait blocks until the request may be sent or the context is done.
+func (l *rateLimiter) wait(ctx context.Context, priority requestPriority) error {
+	l.mu.Lock()
+	l.seq++
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	l.dispatchLocked()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i := range l.waiters {
+			if l.waiters[i] == w {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// the token was granted in the meantime.
+		return nil
+	}
+}
+
+// dispatchLocked hands out the available tokens to the waiters with the highest priority. If waiters are left,
+// it schedules the next dispatch. l.mu must be held.
+func (l *rateLimiter) dispatchLocked() {
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	for len(l.waiters) > 0 && l.tokens >= 1 && !now.Before(l.blockedUntil) {
+		next := 0
+		for i, w := range l.waiters {
+			if w.priority < l.waiters[next].priority ||
+				(w.priority == l.waiters[next].priority && w.seq < l.waiters[next].seq) {
+				next = i
+			}
+		}
+		close(l.waiters[next].ready)
+		l.waiters = append(l.waiters[:next], l.waiters[next+1:]...)
+		l.tokens--
+	}
+
+	if len(l.waiters) == 0 || l.timer != nil {
+		return
+	}
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	if blocked := l.blockedUntil.Sub(now); blocked > delay {
+		delay = blocked
+	}
+	l.timer = time.AfterFunc(delay, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.timer = nil
+		l.dispatchLocked()
+	})
+}
+
+// observe reads the rate limit headers of a response. A 429 response pauses all requests for Retry-After.
+// X-RateLimit-Remaining limits the available tokens, and pauses all requests until X-RateLimit-Reset if it is zero.
+func (l *rateLimiter) observe(resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+		if !ok {
+			retryAfter = defaultRetryAfter
+		}
+		l.blockUntilLocked(now.Add(retryAfter))
+	}
+
+	remaining, err := strconv.ParseFloat(resp.Header.Get("X-RateLimit-Remaining"), 64)
+	if err != nil {
+		return
+	}
+	l.tokens = math.Min(l.tokens, remaining)
+	if remaining > 0 {
+		return
+	}
+	if reset, ok := parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset"), now); ok {
+		l.blockUntilLocked(reset)
+	}
+}
+
+func (l *rateLimiter) blockUntilLocked(t time.Time) {
+	if t.After(l.blockedUntil) {
+		l.blockedUntil = t
+	}
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset parses the X-RateLimit-Reset header, which is either a Unix time or a number of seconds.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	// a number of seconds from now is much smaller than a Unix time.
+	if seconds > 1_000_000_000 {
+		return time.Unix(seconds, 0), true
+	}
+	return now.Add(time.Duration(seconds) * time.Second), true
+}
+
+// rateLimitTransport waits for the rate limiter before each request to the Hivelocity API.
+type rateLimitTransport struct {
+	roundTripper http.RoundTripper
+	limiter      *rateLimiter
+}
+
+// RoundTrip waits for the rate limiter and sends the request.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context(), priorityOf(req)); err != nil {
+		return nil, err
+	}
+	resp, err := t.roundTripper.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	t.limiter.observe(resp)
+	return resp, nil
+}
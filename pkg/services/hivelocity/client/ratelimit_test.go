@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_priorityOf(t *testing.T) {
+	for _, tc := range []struct {
+		method   string
+		url      string
+		expected requestPriority
+	}{
+		{http.MethodDelete, "https://core.hivelocity.net/api/v2/device/1/ipmi/whitelist/", priorityHigh},
+		{http.MethodPost, "https://core.hivelocity.net/api/v2/device/1/power?action=shutdown", priorityHigh},
+		{http.MethodPost, "https://core.hivelocity.net/api/v2/device/1/power?action=boot", priorityNormal},
+		{http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/1", priorityNormal},
+		{http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/", priorityLow},
+	} {
+		req := httptest.NewRequest(tc.method, tc.url, nil)
+		require.Equal(t, tc.expected, priorityOf(req), "%s %s", tc.method, tc.url)
+	}
+}
+
+func Test_rateLimiter_priority(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	limiter := newRateLimiter(1, 1)
+	limiter.now = func() time.Time { return now }
+
+	// use the only token. The clock does not move, so no token gets added.
+	require.NoError(t, limiter.wait(ctx, priorityNormal))
+
+	var mu sync.Mutex
+	var order []requestPriority
+	var wg sync.WaitGroup
+	for i, priority := range []requestPriority{priorityLow, priorityNormal, priorityHigh} {
+		priority := priority
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, limiter.wait(ctx, priority))
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		}()
+		require.Eventually(t, func() bool {
+			limiter.mu.Lock()
+			defer limiter.mu.Unlock()
+			return len(limiter.waiters) == i+1
+		}, time.Second, time.Millisecond)
+	}
+
+	// hand out one token after the other.
+	for i := 0; i < 3; i++ {
+		limiter.mu.Lock()
+		limiter.tokens = 1
+		limiter.dispatchLocked()
+		limiter.mu.Unlock()
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(order) == i+1
+		}, time.Second, time.Millisecond)
+	}
+	wg.Wait()
+	require.Equal(t, []requestPriority{priorityHigh, priorityNormal, priorityLow}, order)
+}
+
+func Test_rateLimiter_contextDone(t *testing.T) {
+	limiter := newRateLimiter(0.001, 1)
+	require.NoError(t, limiter.wait(context.Background(), priorityNormal))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, limiter.wait(ctx, priorityNormal), context.DeadlineExceeded)
+	require.Empty(t, limiter.waiters)
+}
+
+func Test_rateLimiter_observe(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(defaultRequestsPerSecond, defaultBurst)
+	limiter.now = func() time.Time { return now }
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "30")
+	limiter.observe(resp)
+	require.Equal(t, now.Add(30*time.Second), limiter.blockedUntil)
+
+	resp = &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", "3")
+	limiter.observe(resp)
+	require.Equal(t, float64(3), limiter.tokens)
+
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("X-RateLimit-Reset", "120")
+	limiter.observe(resp)
+	require.Equal(t, now.Add(2*time.Minute), limiter.blockedUntil)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	d, ok := parseRetryAfter("5", now)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Second, d)
+
+	d, ok = parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, d)
+
+	_, ok = parseRetryAfter("", now)
+	require.False(t, ok)
+
+	reset, ok := parseRateLimitReset("1700000000", now)
+	require.True(t, ok)
+	require.Equal(t, time.Unix(1700000000, 0), reset)
+}
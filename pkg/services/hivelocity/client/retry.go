@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRetries is the number of retries of an idempotent request after the first attempt.
+	maxRetries = 3
+
+	// retryBaseDelay is the delay before the first retry. It doubles with each retry.
+	retryBaseDelay = 500 * time.Millisecond
+
+	// retryMaxDelay is the maximum delay between two attempts.
+	retryMaxDelay = 5 * time.Second
+)
+
+// retryTransport retries idempotent requests to the Hivelocity API which failed with a transient error.
+// Other requests are sent once, because repeating them could for example reload a device twice.
+type retryTransport struct {
+	roundTripper http.RoundTripper
+
+	// sleep waits for the delay or until the request is cancelled. It can be replaced in tests.
+	sleep func(req *http.Request, d time.Duration) error
+}
+
+// RoundTrip sends the request and retries it with exponential backoff.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return t.roundTripper.RoundTrip(req)
+	}
+
+	sleep := t.sleep
+	if sleep == nil {
+		sleep = sleepWithContext
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTripper.RoundTrip(req)
+		if attempt == maxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		// the body of the request has been consumed by the failed attempt.
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := sleep(req, retryDelay(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isIdempotent returns true if the request can be sent several times without changing the result.
+// Setting the tags of a device replaces all tags, so it is idempotent.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPut:
+		return strings.HasSuffix(req.URL.Path, "/tags") && (req.Body == nil || req.GetBody != nil)
+	default:
+		return false
+	}
+}
+
+// shouldRetry returns true if the request failed with a transient error.
+// Rate limits are not retried here, because the rate limiter waits before the next request.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return isNetworkError(err)
+	}
+	return categoryOfStatusCode(resp.StatusCode) == ErrorCategoryTransient
+}
+
+// retryDelay returns the delay before the retry with exponential backoff and jitter.
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay << attempt
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	// add up to 20% jitter, so that clients don't retry at the same time.
+	return delay + time.Duration(rand.Int63n(int64(delay)/5)) //nolint:gosec // no need for a secure random number
+}
+
+// sleepWithContext waits for the delay. It returns the error of the context if the request gets cancelled.
+func sleepWithContext(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
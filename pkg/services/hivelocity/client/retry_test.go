@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRoundTripper answers requests with the given status codes, one per attempt.
+type fakeRoundTripper struct {
+	statusCodes []int
+	bodies      []string
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		f.bodies = append(f.bodies, string(body))
+	}
+	statusCode := f.statusCodes[0]
+	if len(f.statusCodes) > 1 {
+		f.statusCodes = f.statusCodes[1:]
+	}
+	return &http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func newTestRetryTransport(statusCodes ...int) (*retryTransport, *fakeRoundTripper, *[]time.Duration) {
+	fake := &fakeRoundTripper{statusCodes: statusCodes}
+	var delays []time.Duration
+	return &retryTransport{
+		roundTripper: fake,
+		sleep: func(_ *http.Request, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}, fake, &delays
+}
+
+func Test_retryTransport_retriesIdempotentRequests(t *testing.T) {
+	transport, _, delays := newTestRetryTransport(http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+
+	req, err := http.NewRequest(http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/1", http.NoBody)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, *delays, 2)
+	require.Less(t, (*delays)[0], (*delays)[1])
+}
+
+func Test_retryTransport_givesUp(t *testing.T) {
+	transport, _, delays := newTestRetryTransport(http.StatusInternalServerError)
+
+	req, err := http.NewRequest(http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/", http.NoBody)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Len(t, *delays, maxRetries)
+}
+
+func Test_retryTransport_replaysBodyOfTagRequests(t *testing.T) {
+	transport, fake, delays := newTestRetryTransport(http.StatusGatewayTimeout, http.StatusOK)
+
+	req, err := http.NewRequest(http.MethodPut, "https://core.hivelocity.net/api/v2/device/1/tags", bytes.NewBufferString(`{"tags":["a"]}`))
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, *delays, 1)
+	require.Equal(t, []string{`{"tags":["a"]}`, `{"tags":["a"]}`}, fake.bodies)
+}
+
+func Test_retryTransport_doesNotRetry(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		method     string
+		url        string
+		statusCode int
+	}{
+		{"provision", http.MethodPut, "https://core.hivelocity.net/api/v2/bare-metal-devices/1", http.StatusServiceUnavailable},
+		{"power", http.MethodPost, "https://core.hivelocity.net/api/v2/device/1/power?action=boot", http.StatusServiceUnavailable},
+		{"bad request", http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/1", http.StatusBadRequest},
+		{"rate limit", http.MethodGet, "https://core.hivelocity.net/api/v2/bare-metal-devices/1", http.StatusTooManyRequests},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			transport, _, delays := newTestRetryTransport(tc.statusCode)
+			req, err := http.NewRequest(tc.method, tc.url, http.NoBody)
+			require.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			require.Equal(t, tc.statusCode, resp.StatusCode)
+			require.Empty(t, *delays)
+		})
+	}
+}
+
+func Test_retryDelay(t *testing.T) {
+	require.GreaterOrEqual(t, retryDelay(0), retryBaseDelay)
+	require.Less(t, retryDelay(0), 2*retryBaseDelay)
+	require.LessOrEqual(t, retryDelay(10), retryMaxDelay+retryMaxDelay/5)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/tracing"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingClient creates a span for each call of the wrapped client.
+type tracingClient struct {
+	client Client
+}
+
+var _ Client = &tracingClient{}
+
+// NewTracingClient returns a client which creates a span "hvclient.<method>" for each method call.
+// Methods for a device have the device ID as attribute.
+func NewTracingClient(client Client) Client {
+	return &tracingClient{client: client}
+}
+
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "hvclient."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (c *tracingClient) PowerOnDevice(ctx context.Context, deviceID int32) (err error) {
+	ctx, span := startSpan(ctx, "PowerOnDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.PowerOnDevice(ctx, deviceID)
+}
+
+func (c *tracingClient) ShutdownDevice(ctx context.Context, deviceID int32) (err error) {
+	ctx, span := startSpan(ctx, "ShutdownDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.ShutdownDevice(ctx, deviceID)
+}
+
+func (c *tracingClient) PowerOffDevice(ctx context.Context, deviceID int32) (err error) {
+	ctx, span := startSpan(ctx, "PowerOffDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.PowerOffDevice(ctx, deviceID)
+}
+
+func (c *tracingClient) RebootDevice(ctx context.Context, deviceID int32) (err error) {
+	ctx, span := startSpan(ctx, "RebootDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.RebootDevice(ctx, deviceID)
+}
+
+func (c *tracingClient) PowerCycleDevice(ctx context.Context, deviceID int32) (err error) {
+	ctx, span := startSpan(ctx, "PowerCycleDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.PowerCycleDevice(ctx, deviceID)
+}
+
+func (c *tracingClient) HardResetDevice(ctx context.Context, deviceID int32) (err error) {
+	ctx, span := startSpan(ctx, "HardResetDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.HardResetDevice(ctx, deviceID)
+}
+
+func (c *tracingClient) GetDevicePowerStatus(ctx context.Context, deviceID int32) (_ string, err error) {
+	ctx, span := startSpan(ctx, "GetDevicePowerStatus", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.GetDevicePowerStatus(ctx, deviceID)
+}
+
+func (c *tracingClient) ProvisionDevice(ctx context.Context, deviceID int32, opts hv.BareMetalDeviceUpdate) (_ hv.BareMetalDevice, err error) {
+	ctx, span := startSpan(ctx, "ProvisionDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.ProvisionDevice(ctx, deviceID, opts)
+}
+
+func (c *tracingClient) ListDevices(ctx context.Context) (_ []hv.BareMetalDevice, err error) {
+	ctx, span := startSpan(ctx, "ListDevices")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListDevices(ctx)
+}
+
+func (c *tracingClient) ListImages(ctx context.Context, productID int32) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "ListImages")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListImages(ctx, productID)
+}
+
+func (c *tracingClient) ListSSHKeys(ctx context.Context) (_ []hv.SshKeyResponse, err error) {
+	ctx, span := startSpan(ctx, "ListSSHKeys")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListSSHKeys(ctx)
+}
+
+func (c *tracingClient) GetDevice(ctx context.Context, deviceID int32) (_ hv.BareMetalDevice, err error) {
+	ctx, span := startSpan(ctx, "GetDevice", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.GetDevice(ctx, deviceID)
+}
+
+func (c *tracingClient) SetDeviceTags(ctx context.Context, deviceID int32, tags []string) (err error) {
+	ctx, span := startSpan(ctx, "SetDeviceTags", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.SetDeviceTags(ctx, deviceID, tags)
+}
+
+func (c *tracingClient) GetDeviceDump(ctx context.Context, deviceID int32) (_ hv.DeviceDump, err error) {
+	ctx, span := startSpan(ctx, "GetDeviceDump", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.GetDeviceDump(ctx, deviceID)
+}
+
+func (c *tracingClient) ListDeviceIPAssignments(ctx context.Context, deviceID int32) (_ []hv.IpAssignment, err error) {
+	ctx, span := startSpan(ctx, "ListDeviceIPAssignments", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListDeviceIPAssignments(ctx, deviceID)
+}
+
+func (c *tracingClient) ListDevicePorts(ctx context.Context, deviceID int32) (_ []hv.DevicePort, err error) {
+	ctx, span := startSpan(ctx, "ListDevicePorts", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListDevicePorts(ctx, deviceID)
+}
+
+func (c *tracingClient) ListDeviceIPMISensors(ctx context.Context, deviceID int32) (_ []IPMISensor, err error) {
+	ctx, span := startSpan(ctx, "ListDeviceIPMISensors", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListDeviceIPMISensors(ctx, deviceID)
+}
+
+func (c *tracingClient) ListDeviceEvents(ctx context.Context, deviceID int32) (_ []hv.DeviceEvent, err error) {
+	ctx, span := startSpan(ctx, "ListDeviceEvents", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListDeviceEvents(ctx, deviceID)
+}
+
+func (c *tracingClient) CreateTicket(ctx context.Context, subject, body string) (_ int32, err error) {
+	ctx, span := startSpan(ctx, "CreateTicket")
+	defer func() { tracing.End(span, err) }()
+	return c.client.CreateTicket(ctx, subject, body)
+}
+
+func (c *tracingClient) GetTicket(ctx context.Context, ticketID int32) (_ hv.Ticket, err error) {
+	ctx, span := startSpan(ctx, "GetTicket")
+	defer func() { tracing.End(span, err) }()
+	return c.client.GetTicket(ctx, ticketID)
+}
+
+func (c *tracingClient) ListTickets(ctx context.Context) (_ []hv.Ticket, err error) {
+	ctx, span := startSpan(ctx, "ListTickets")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListTickets(ctx)
+}
+
+func (c *tracingClient) BondDevicePorts(ctx context.Context, deviceID int32) (_ hv.NetworkTaskDump, err error) {
+	ctx, span := startSpan(ctx, "BondDevicePorts", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.BondDevicePorts(ctx, deviceID)
+}
+
+func (c *tracingClient) UnbondDevicePorts(ctx context.Context, deviceID int32) (_ hv.NetworkTaskDump, err error) {
+	ctx, span := startSpan(ctx, "UnbondDevicePorts", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.UnbondDevicePorts(ctx, deviceID)
+}
+
+func (c *tracingClient) GetNetworkTask(ctx context.Context, taskID string) (_ hv.NetworkTaskDump, err error) {
+	ctx, span := startSpan(ctx, "GetNetworkTask")
+	defer func() { tracing.End(span, err) }()
+	return c.client.GetNetworkTask(ctx, taskID)
+}
+
+func (c *tracingClient) AddIPMIWhitelistIP(ctx context.Context, deviceID int32, ip string) (err error) {
+	ctx, span := startSpan(ctx, "AddIPMIWhitelistIP", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.AddIPMIWhitelistIP(ctx, deviceID, ip)
+}
+
+func (c *tracingClient) ClearIPMIWhitelist(ctx context.Context, deviceID int32) (err error) {
+	ctx, span := startSpan(ctx, "ClearIPMIWhitelist", tracing.DeviceID(deviceID))
+	defer func() { tracing.End(span, err) }()
+	return c.client.ClearIPMIWhitelist(ctx, deviceID)
+}
+
+func (c *tracingClient) ListNullRoutes(ctx context.Context) (_ []hv.NullRoute, err error) {
+	ctx, span := startSpan(ctx, "ListNullRoutes")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListNullRoutes(ctx)
+}
+
+func (c *tracingClient) ListARecords(ctx context.Context, zone string) (_ []hv.ARecord, err error) {
+	ctx, span := startSpan(ctx, "ListARecords")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListARecords(ctx, zone)
+}
+
+func (c *tracingClient) CreateARecord(ctx context.Context, zone string, record hv.ARecord) (err error) {
+	ctx, span := startSpan(ctx, "CreateARecord")
+	defer func() { tracing.End(span, err) }()
+	return c.client.CreateARecord(ctx, zone, record)
+}
+
+func (c *tracingClient) UpdateARecord(ctx context.Context, zone string, record hv.ARecord) (err error) {
+	ctx, span := startSpan(ctx, "UpdateARecord")
+	defer func() { tracing.End(span, err) }()
+	return c.client.UpdateARecord(ctx, zone, record)
+}
+
+func (c *tracingClient) DeleteARecord(ctx context.Context, zone string, name string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteARecord")
+	defer func() { tracing.End(span, err) }()
+	return c.client.DeleteARecord(ctx, zone, name)
+}
+
+func (c *tracingClient) ListAAAARecords(ctx context.Context, zone string) (_ []hv.AaaaRecordReturn, err error) {
+	ctx, span := startSpan(ctx, "ListAAAARecords")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListAAAARecords(ctx, zone)
+}
+
+func (c *tracingClient) CreateAAAARecord(ctx context.Context, zone string, record hv.AaaaRecordCreate) (err error) {
+	ctx, span := startSpan(ctx, "CreateAAAARecord")
+	defer func() { tracing.End(span, err) }()
+	return c.client.CreateAAAARecord(ctx, zone, record)
+}
+
+func (c *tracingClient) DeleteAAAARecord(ctx context.Context, zone string, recordID int32) (err error) {
+	ctx, span := startSpan(ctx, "DeleteAAAARecord")
+	defer func() { tracing.End(span, err) }()
+	return c.client.DeleteAAAARecord(ctx, zone, recordID)
+}
+
+func (c *tracingClient) ListPTRRecords(ctx context.Context) (_ []hv.PtrRecordReturn, err error) {
+	ctx, span := startSpan(ctx, "ListPTRRecords")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ListPTRRecords(ctx)
+}
+
+func (c *tracingClient) UpdatePTRRecord(ctx context.Context, recordID int32, update hv.PtrRecordUpdate) (err error) {
+	ctx, span := startSpan(ctx, "UpdatePTRRecord")
+	defer func() { tracing.End(span, err) }()
+	return c.client.UpdatePTRRecord(ctx, recordID, update)
+}
+
+func (c *tracingClient) ValidateAPIKey(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "ValidateAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return c.client.ValidateAPIKey(ctx)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hvclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/tracing"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// getDeviceClient implements GetDevice. Calling other methods panics.
+type getDeviceClient struct {
+	Client
+	err error
+}
+
+func (c *getDeviceClient) GetDevice(_ context.Context, deviceID int32) (hv.BareMetalDevice, error) {
+	return hv.BareMetalDevice{DeviceId: deviceID}, c.err
+}
+
+func Test_tracingClient(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	client := NewTracingClient(&getDeviceClient{})
+	device, err := client.GetDevice(context.Background(), 42)
+	require.NoError(t, err)
+	require.Equal(t, int32(42), device.DeviceId)
+
+	client = NewTracingClient(&getDeviceClient{err: ErrDeviceNotFound})
+	_, err = client.GetDevice(context.Background(), 43)
+	require.ErrorIs(t, err, ErrDeviceNotFound)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "hvclient.GetDevice", spans[0].Name)
+	require.Contains(t, spans[0].Attributes, tracing.DeviceID(42))
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+
+	require.Contains(t, spans[1].Attributes, tracing.DeviceID(43))
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Len(t, spans[1].Events, 1) // the recorded error
+}
@@ -67,6 +67,7 @@ func (r actionError) Result() (result reconcile.Result, err error) {
 // and that the resource should transition to a previous state.
 type actionGoBack struct {
 	nextState infrav1.ProvisioningState
+	reason    string
 }
 
 func (r actionGoBack) Result() (result reconcile.Result, err error) {
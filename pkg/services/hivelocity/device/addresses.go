@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// addressDiscoveryInterval is the time between two discoveries of the IP addresses of a device.
+const addressDiscoveryInterval = 10 * time.Minute
+
+// reconcileDeviceAddresses returns the IP addresses of the device. They are discovered at most once per
+// addressDiscoveryInterval, or earlier if the primary IP of the device changed. Errors are only logged, because
+// they must not block the machine from becoming ready: the known addresses are kept, or the primary IP is used.
+func (s *Service) reconcileDeviceAddresses(ctx context.Context, device hv.BareMetalDevice) []clusterv1.MachineAddress {
+	hvMachine := s.scope.HivelocityMachine
+	known := ipAddresses(hvMachine.Status.Addresses)
+	primaryUnchanged := len(known) > 0 && known[0].Address == device.PrimaryIp
+	if primaryUnchanged && hvMachine.Status.LastAddressDiscovery != nil &&
+		!hasTimedOut(hvMachine.Status.LastAddressDiscovery, addressDiscoveryInterval) {
+		return known
+	}
+	now := metav1.Now()
+	hvMachine.Status.LastAddressDiscovery = &now
+
+	addresses, err := s.getDeviceAddresses(ctx, device)
+	if err != nil {
+		s.handleRateLimitExceeded(err, "getDeviceAddresses")
+		s.scope.Error(err, "failed to discover addresses of device, keeping the known addresses", "deviceID", device.DeviceId)
+		if primaryUnchanged {
+			return known
+		}
+		return nil
+	}
+	return addresses
+}
+
+// ipAddresses returns the addresses without the host name.
+func ipAddresses(addresses []clusterv1.MachineAddress) []clusterv1.MachineAddress {
+	var result []clusterv1.MachineAddress
+	for _, address := range addresses {
+		if address.Type != clusterv1.MachineHostName {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+// getDeviceAddresses discovers the IPv4 and IPv6 addresses of the device with the IP assignment and device port APIs.
+func (s *Service) getDeviceAddresses(ctx context.Context, device hv.BareMetalDevice) ([]clusterv1.MachineAddress, error) {
+	assignments, err := s.scope.HVClient.ListDeviceIPAssignments(ctx, device.DeviceId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IP assignments of device %d: %w", device.DeviceId, err)
+	}
+	ports, err := s.scope.HVClient.ListDevicePorts(ctx, device.DeviceId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ports of device %d: %w", device.DeviceId, err)
+	}
+	return deviceAddresses(device, assignments, ports), nil
+}
+
+// deviceAddresses returns the IP addresses of the device. The primary IP comes first, followed by the
+// other IPv4 and then the IPv6 addresses. Addresses of public ports are published as internal and
+// external IP like the primary IP, addresses of private ports only as internal IP.
+func deviceAddresses(device hv.BareMetalDevice, assignments []hv.IpAssignment, ports []hv.DevicePort) []clusterv1.MachineAddress {
+	isPrivate := make(map[string]bool)
+	var primary, others []net.IP
+
+	add := func(address string, private bool) {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return
+		}
+		if _, found := isPrivate[ip.String()]; found {
+			return
+		}
+		isPrivate[ip.String()] = private
+		if address == device.PrimaryIp {
+			primary = append(primary, ip)
+			return
+		}
+		others = append(others, ip)
+	}
+
+	add(device.PrimaryIp, false)
+
+	privatePorts := make(map[int32]bool, len(ports))
+	for _, port := range ports {
+		privatePorts[port.PortId] = port.Private
+		for _, assignment := range port.Ips {
+			for _, address := range assignmentAddresses(assignment) {
+				add(address, port.Private)
+			}
+		}
+	}
+
+	// assignments which are statically routed to the device are not applied to a port
+	for _, assignment := range assignments {
+		for _, address := range assignmentAddresses(assignment) {
+			add(address, privatePorts[assignment.PortId])
+		}
+	}
+
+	sort.Slice(others, func(i, j int) bool {
+		iIsIPv4, jIsIPv4 := others[i].To4() != nil, others[j].To4() != nil
+		if iIsIPv4 != jIsIPv4 {
+			return iIsIPv4
+		}
+		return bytes.Compare(others[i].To16(), others[j].To16()) < 0
+	})
+
+	addresses := make([]clusterv1.MachineAddress, 0, 2*(len(primary)+len(others)))
+	for _, ip := range append(primary, others...) {
+		addresses = append(addresses, clusterv1.MachineAddress{
+			Type:    clusterv1.MachineInternalIP,
+			Address: ip.String(),
+		})
+		if !isPrivate[ip.String()] {
+			addresses = append(addresses, clusterv1.MachineAddress{
+				Type:    clusterv1.MachineExternalIP,
+				Address: ip.String(),
+			})
+		}
+	}
+	return addresses
+}
+
+// assignmentAddresses returns the addresses of an IP assignment which can be used by the device.
+// UsableIps is only filled for IPv4 assignments. The device uses the first usable IP of IPv6 assignments.
+func assignmentAddresses(assignment hv.IpAssignment) []string {
+	if len(assignment.UsableIps) > 0 {
+		return assignment.UsableIps
+	}
+	if assignment.FirstUsableIp != "" {
+		return []string{assignment.FirstUsableIp}
+	}
+	return nil
+}
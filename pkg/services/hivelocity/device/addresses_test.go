@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func Test_deviceAddresses(t *testing.T) {
+	device := hv.BareMetalDevice{DeviceId: 1, PrimaryIp: "192.0.2.10"}
+
+	tests := []struct {
+		description string
+		assignments []hv.IpAssignment
+		ports       []hv.DevicePort
+		want        []clusterv1.MachineAddress
+	}{
+		{
+			description: "only primary IP",
+			want: []clusterv1.MachineAddress{
+				{Type: clusterv1.MachineInternalIP, Address: "192.0.2.10"},
+				{Type: clusterv1.MachineExternalIP, Address: "192.0.2.10"},
+			},
+		},
+		{
+			description: "dual-stack public port and private port",
+			ports: []hv.DevicePort{
+				{
+					PortId: 10,
+					Ips: []hv.IpAssignment{
+						{Version: 4, PortId: 10, UsableIps: []string{"192.0.2.10", "192.0.2.11"}},
+						{Version: 6, PortId: 10, Subnet: "2001:db8::/64", FirstUsableIp: "2001:0db8:0000::2"},
+					},
+				},
+				{
+					PortId:  11,
+					Private: true,
+					Ips: []hv.IpAssignment{
+						{Version: 4, PortId: 11, UsableIps: []string{"10.0.0.5"}},
+					},
+				},
+			},
+			assignments: []hv.IpAssignment{
+				{Version: 4, PortId: 10, UsableIps: []string{"192.0.2.11"}},
+				{Version: 6, PortId: 11, Subnet: "fd00::/64", FirstUsableIp: "fd00::2"},
+			},
+			want: []clusterv1.MachineAddress{
+				{Type: clusterv1.MachineInternalIP, Address: "192.0.2.10"},
+				{Type: clusterv1.MachineExternalIP, Address: "192.0.2.10"},
+				{Type: clusterv1.MachineInternalIP, Address: "10.0.0.5"},
+				{Type: clusterv1.MachineInternalIP, Address: "192.0.2.11"},
+				{Type: clusterv1.MachineExternalIP, Address: "192.0.2.11"},
+				{Type: clusterv1.MachineInternalIP, Address: "2001:db8::2"},
+				{Type: clusterv1.MachineExternalIP, Address: "2001:db8::2"},
+				{Type: clusterv1.MachineInternalIP, Address: "fd00::2"},
+			},
+		},
+		{
+			description: "statically routed assignment without port",
+			assignments: []hv.IpAssignment{
+				{Version: 6, Subnet: "2001:db8:1::/64", FirstUsableIp: "2001:db8:1::2", NextHopIp: "192.0.2.10"},
+			},
+			want: []clusterv1.MachineAddress{
+				{Type: clusterv1.MachineInternalIP, Address: "192.0.2.10"},
+				{Type: clusterv1.MachineExternalIP, Address: "192.0.2.10"},
+				{Type: clusterv1.MachineInternalIP, Address: "2001:db8:1::2"},
+				{Type: clusterv1.MachineExternalIP, Address: "2001:db8:1::2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			require.Equal(t, tt.want, deviceAddresses(device, tt.assignments, tt.ports))
+		})
+	}
+}
+
+func Test_reconcileDeviceAddresses(t *testing.T) {
+	hvMachine := &infrav1.HivelocityMachine{}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: mockclient.NewMockedHVClientFactory().NewClient("dummy-key")},
+			HivelocityMachine: hvMachine,
+		},
+	}
+	primaryOnly := []clusterv1.MachineAddress{
+		{Type: clusterv1.MachineInternalIP, Address: "192.0.2.10"},
+		{Type: clusterv1.MachineExternalIP, Address: "192.0.2.10"},
+	}
+
+	device := hv.BareMetalDevice{DeviceId: mockclient.FreeDeviceID, PrimaryIp: "192.0.2.10"}
+	addresses := service.reconcileDeviceAddresses(context.Background(), device)
+	require.Equal(t, primaryOnly, addresses)
+	require.NotNil(t, hvMachine.Status.LastAddressDiscovery)
+	hvMachine.SetMachineStatus(device, addresses)
+
+	// addresses are not discovered again within the interval, so the unknown device does not fail
+	unknownDevice := hv.BareMetalDevice{DeviceId: -1, PrimaryIp: "192.0.2.10"}
+	require.Equal(t, primaryOnly, service.reconcileDeviceAddresses(context.Background(), unknownDevice))
+
+	// failed discoveries keep the known addresses
+	hvMachine.Status.LastAddressDiscovery = nil
+	require.Equal(t, primaryOnly, service.reconcileDeviceAddresses(context.Background(), unknownDevice))
+
+	// a changed primary IP is discovered again. If that fails, SetMachineStatus falls back to the primary IP.
+	unknownDevice.PrimaryIp = "192.0.2.11"
+	require.Nil(t, service.reconcileDeviceAddresses(context.Background(), unknownDevice))
+}
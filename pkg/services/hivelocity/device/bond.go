@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+)
+
+// errBondFailed indicates that the network task which bonds the NICs failed.
+var errBondFailed = errors.New("failed to bond NICs")
+
+// reconcileBond bonds the NICs of the device if it is configured in the spec. It returns nil once the NICs are bonded,
+// so that provisioning can go on. The ID of the running network task is kept in the status.
+func (s *Service) reconcileBond(ctx context.Context, deviceID int32) actionResult {
+	hvMachine := s.scope.HivelocityMachine
+	if !hvMachine.BondEnabled() {
+		conditions.Delete(hvMachine, infrav1.DeviceBondedCondition)
+		return nil
+	}
+	if conditions.IsTrue(hvMachine, infrav1.DeviceBondedCondition) {
+		return nil
+	}
+
+	if hvMachine.Spec.Status.BondTaskID == "" {
+		ports, err := s.scope.HVClient.ListDevicePorts(ctx, deviceID)
+		if err != nil {
+			s.handleRateLimitExceeded(err, "ListDevicePorts")
+			return actionError{err: fmt.Errorf("failed to list device ports: %w", err)}
+		}
+		if hasBondPort(ports) {
+			conditions.MarkTrue(hvMachine, infrav1.DeviceBondedCondition)
+			return nil
+		}
+
+		task, err := s.scope.HVClient.BondDevicePorts(ctx, deviceID)
+		if err != nil {
+			s.handleRateLimitExceeded(err, "BondDevicePorts")
+			return actionError{err: fmt.Errorf("failed to bond device ports: %w", err)}
+		}
+		record.Eventf(hvMachine, "BondDevicePorts", "Started to bond NICs of device %d", deviceID)
+		hvMachine.Spec.Status.BondTaskID = task.TaskId
+	}
+
+	task, err := s.scope.HVClient.GetNetworkTask(ctx, hvMachine.Spec.Status.BondTaskID)
+	if err != nil {
+		s.handleRateLimitExceeded(err, "GetNetworkTask")
+		if errors.Is(err, hvclient.ErrNetworkTaskNotFound) {
+			// start over
+			hvMachine.Spec.Status.BondTaskID = ""
+		}
+		return actionError{err: fmt.Errorf("failed to get network task: %w", err)}
+	}
+
+	switch task.Result {
+	case hvclient.NetworkTaskResultSuccess:
+		hvMachine.Spec.Status.BondTaskID = ""
+		conditions.MarkTrue(hvMachine, infrav1.DeviceBondedCondition)
+		return nil
+	case hvclient.NetworkTaskResultFailed:
+		hvMachine.Spec.Status.BondTaskID = ""
+		msg := fmt.Sprintf("network task %s to bond NICs of device %d failed", task.TaskId, deviceID)
+		record.Warnf(hvMachine, "BondDevicePortsFailed", msg)
+		conditions.MarkFalse(hvMachine, infrav1.DeviceBondedCondition, infrav1.BondFailedReason,
+			clusterv1.ConditionSeverityError, msg)
+		return actionError{err: fmt.Errorf("%w: %s", errBondFailed, msg)}
+	default:
+		conditions.MarkFalse(hvMachine, infrav1.DeviceBondedCondition, infrav1.BondPendingReason,
+			clusterv1.ConditionSeverityInfo, "waiting for network task %s", task.TaskId)
+		return actionContinue{delay: 10 * time.Second}
+	}
+}
+
+// removeBond removes the bond of the NICs, if it was configured in the spec.
+func (s *Service) removeBond(ctx context.Context, deviceID int32) error {
+	hvMachine := s.scope.HivelocityMachine
+	if !hvMachine.BondEnabled() {
+		return nil
+	}
+	ports, err := s.scope.HVClient.ListDevicePorts(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to list device ports: %w", err)
+	}
+	if hasBondPort(ports) {
+		if _, err := s.scope.HVClient.UnbondDevicePorts(ctx, deviceID); err != nil {
+			return fmt.Errorf("failed to remove bond: %w", err)
+		}
+	}
+	hvMachine.Spec.Status.BondTaskID = ""
+	conditions.Delete(hvMachine, infrav1.DeviceBondedCondition)
+	return nil
+}
+
+// hasBondPort returns true if one of the ports is a bond interface.
+func hasBondPort(ports []hv.DevicePort) bool {
+	for _, port := range ports {
+		if port.Type_ == hvclient.PortTypeBond {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"testing"
+
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_hasBondPort(t *testing.T) {
+	require.False(t, hasBondPort(nil))
+	require.False(t, hasBondPort([]hv.DevicePort{{PortId: 1, Type_: "ethernet"}, {PortId: 2, Type_: "ethernet"}}))
+	require.True(t, hasBondPort([]hv.DevicePort{{PortId: 1, Type_: "ethernet"}, {PortId: 3, Type_: "bond"}}))
+}
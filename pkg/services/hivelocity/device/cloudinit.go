@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"gopkg.in/yaml.v3"
+)
+
+// nftablesConfigPath is the config which gets loaded by the nftables service.
+const nftablesConfigPath = "/etc/nftables.conf"
+
+var errInvalidCloudConfig = fmt.Errorf("invalid cloud-config")
+
+// cloudConfigWithNetworkPolicy adds the nftables rules of the network policy to the cloud-config.
+// Comment lines at the beginning, like "## template: jinja", are kept as they are.
+func cloudConfigWithNetworkPolicy(cloudConfig []byte, policy *infrav1.NetworkPolicy) ([]byte, error) {
+	if policy == nil {
+		return cloudConfig, nil
+	}
+
+	lines := strings.SplitAfter(string(cloudConfig), "\n")
+	var header string
+	for len(lines) > 0 {
+		trimmed := strings.TrimSpace(lines[0])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		header += lines[0]
+		lines = lines[1:]
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "")), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-config: %w", err)
+	}
+	if doc.Kind == 0 {
+		// empty cloud-config
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: expected a mapping", errInvalidCloudConfig)
+	}
+
+	var file yaml.Node
+	if err := file.Encode(map[string]string{
+		"path":        nftablesConfigPath,
+		"owner":       "root:root",
+		"permissions": "0644",
+		"content":     nftablesRules(policy),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to encode nftables config: %w", err)
+	}
+	writeFiles, err := sequenceOfMapping(root, "write_files")
+	if err != nil {
+		return nil, err
+	}
+	writeFiles.Content = append(writeFiles.Content, &file)
+
+	// the firewall gets applied before kubeadm runs
+	var commands yaml.Node
+	if err := commands.Encode([]string{
+		"systemctl enable nftables",
+		"nft -f " + nftablesConfigPath,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to encode commands: %w", err)
+	}
+	runCmd, err := sequenceOfMapping(root, "runcmd")
+	if err != nil {
+		return nil, err
+	}
+	runCmd.Content = append(commands.Content, runCmd.Content...)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode cloud-config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode cloud-config: %w", err)
+	}
+	return append([]byte(header), buf.Bytes()...), nil
+}
+
+// sequenceOfMapping returns the sequence of the key in the mapping. The sequence gets created if it does not exist.
+func sequenceOfMapping(mapping *yaml.Node, key string) (*yaml.Node, error) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		value := mapping.Content[i+1]
+		switch {
+		case value.Kind == yaml.SequenceNode:
+			return value, nil
+		case value.Kind == yaml.ScalarNode && value.Tag == "!!null":
+			*value = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			return value, nil
+		default:
+			return nil, fmt.Errorf("%w: %q is not a list", errInvalidCloudConfig, key)
+		}
+	}
+	value := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value, nil
+}
+
+// nftablesRules returns the nftables config of the network policy. The rules are kept in their own table,
+// so that the rules of kube-proxy and the CNI are not touched.
+func nftablesRules(policy *infrav1.NetworkPolicy) string {
+	var ipv4CIDRs, ipv6CIDRs []string
+	for _, cidr := range policy.AllowedSourceCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			// validated by the webhook
+			continue
+		}
+		if network.IP.To4() != nil {
+			ipv4CIDRs = append(ipv4CIDRs, network.String())
+		} else {
+			ipv6CIDRs = append(ipv6CIDRs, network.String())
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("#!/usr/sbin/nft -f\n")
+	b.WriteString("# managed by cluster-api-provider-hivelocity\n")
+	b.WriteString("table inet caphv\n")
+	b.WriteString("delete table inet caphv\n")
+	b.WriteString("table inet caphv {\n")
+	b.WriteString("  chain input {\n")
+	b.WriteString("    type filter hook input priority filter; policy drop;\n")
+	b.WriteString("    ct state established,related accept\n")
+	b.WriteString("    iifname \"lo\" accept\n")
+	b.WriteString("    meta l4proto { icmp, ipv6-icmp } accept\n")
+	b.WriteString("    ip saddr { 10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16 } accept\n")
+	b.WriteString("    ip6 saddr fc00::/7 accept\n")
+	if len(ipv4CIDRs) > 0 {
+		fmt.Fprintf(&b, "    ip saddr { %s } accept\n", strings.Join(ipv4CIDRs, ", "))
+	}
+	if len(ipv6CIDRs) > 0 {
+		fmt.Fprintf(&b, "    ip6 saddr { %s } accept\n", strings.Join(ipv6CIDRs, ", "))
+	}
+	if len(policy.AllowedTCPPorts) > 0 {
+		fmt.Fprintf(&b, "    tcp dport { %s } accept\n", joinPorts(policy.AllowedTCPPorts))
+	}
+	if len(policy.AllowedUDPPorts) > 0 {
+		fmt.Fprintf(&b, "    udp dport { %s } accept\n", joinPorts(policy.AllowedUDPPorts))
+	}
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func joinPorts(ports []int32) string {
+	s := make([]string, 0, len(ports))
+	for _, port := range ports {
+		s = append(s, fmt.Sprint(port))
+	}
+	return strings.Join(s, ", ")
+}
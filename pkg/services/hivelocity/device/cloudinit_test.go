@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"strings"
+	"testing"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_cloudConfigWithNetworkPolicy(t *testing.T) {
+	cloudConfig := `## template: jinja
+#cloud-config
+
+write_files:
+- path: /etc/kubernetes/pki/ca.crt
+  owner: root:root
+  permissions: '0640'
+  content: |
+    cert
+runcmd:
+  - 'kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml'
+hostname: '{{ ds.meta_data.local_hostname }}'
+`
+	policy := &infrav1.NetworkPolicy{
+		AllowedTCPPorts:    []int32{22, 6443},
+		AllowedSourceCIDRs: []string{"192.0.2.0/24", "2001:db8::/64"},
+	}
+
+	out, err := cloudConfigWithNetworkPolicy([]byte(cloudConfig), policy)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(out), "## template: jinja\n#cloud-config\n\n"), string(out))
+
+	var config struct {
+		WriteFiles []map[string]string `yaml:"write_files"`
+		RunCmd     []string            `yaml:"runcmd"`
+		Hostname   string              `yaml:"hostname"`
+	}
+	require.NoError(t, yaml.Unmarshal(out, &config))
+	require.Len(t, config.WriteFiles, 2)
+	require.Equal(t, "0640", config.WriteFiles[0]["permissions"])
+	require.Equal(t, nftablesConfigPath, config.WriteFiles[1]["path"])
+	require.Contains(t, config.WriteFiles[1]["content"], "tcp dport { 22, 6443 } accept")
+	require.Contains(t, config.WriteFiles[1]["content"], "ip saddr { 192.0.2.0/24 } accept")
+	require.Contains(t, config.WriteFiles[1]["content"], "ip6 saddr { 2001:db8::/64 } accept")
+	require.Equal(t, []string{
+		"systemctl enable nftables",
+		"nft -f " + nftablesConfigPath,
+		"kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml",
+	}, config.RunCmd)
+	require.Equal(t, "{{ ds.meta_data.local_hostname }}", config.Hostname)
+}
+
+func Test_cloudConfigWithNetworkPolicy_noPolicy(t *testing.T) {
+	cloudConfig := []byte("runcmd: [foo]\n")
+	out, err := cloudConfigWithNetworkPolicy(cloudConfig, nil)
+	require.NoError(t, err)
+	require.Equal(t, cloudConfig, out)
+}
+
+func Test_cloudConfigWithNetworkPolicy_invalid(t *testing.T) {
+	_, err := cloudConfigWithNetworkPolicy([]byte("runcmd: foo\n"), &infrav1.NetworkPolicy{})
+	require.ErrorIs(t, err, errInvalidCloudConfig)
+
+	out, err := cloudConfigWithNetworkPolicy([]byte(""), &infrav1.NetworkPolicy{})
+	require.NoError(t, err)
+	require.Contains(t, string(out), nftablesConfigPath)
+}
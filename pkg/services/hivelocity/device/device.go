@@ -28,6 +28,7 @@ import (
 
 	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
 	hvlabels "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/labels"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
 	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
@@ -51,6 +52,9 @@ type Service struct {
 
 const (
 	defaultImageName = "Ubuntu 20.x"
+
+	// reloadingTooLongReason is the reason in the metrics of devices which were quarantined because they reloaded too long.
+	reloadingTooLongReason = "reloading-too-long"
 )
 
 var (
@@ -130,6 +134,18 @@ func (s *Service) actionAssociateDevice(ctx context.Context) actionResult {
 	}
 	conditions.Delete(s.scope.HivelocityMachine, infrav1.DeviceAssociateSucceededCondition)
 
+	// the list of devices is cached. Check that the device is still free before claiming it.
+	current, err := s.scope.HVClient.GetDevice(ctx, device.DeviceId)
+	if err != nil {
+		return s.actionForAPIError(err, "GetDevice")
+	}
+	if free, _ := findAvailableDeviceFromList(ctx, []hv.BareMetalDevice{current}, s.scope.HivelocityMachine.Spec.DeviceSelector,
+		s.scope.HivelocityCluster.Name); free == nil {
+		log.Info("device was claimed in the meantime, trying again", "DeviceId", device.DeviceId)
+		return actionContinue{delay: 5 * time.Second}
+	}
+	device = &current
+
 	// associate this device with the machine object by setting tags
 	device.Tags = append(device.Tags,
 		s.scope.HivelocityCluster.DeviceTagOwned().ToString(),
@@ -285,14 +301,13 @@ func (s *Service) actionVerifyAssociate(ctx context.Context) actionResult {
 
 	device, err := s.scope.HVClient.GetDevice(ctx, deviceID)
 	if err != nil {
-		s.handleRateLimitExceeded(err, "GetDevice")
 		if errors.Is(err, hvclient.ErrDeviceNotFound) {
 			// if device cannot be found, we associate a new one
 			log.Info("Device not found. Go back to StateAssociateDevice")
 			record.Warnf(s.scope.HivelocityMachine, "DeviceNotFound", "Hivelocity device not found. Associate new one")
-			return actionGoBack{nextState: infrav1.StateAssociateDevice}
+			return actionGoBack{nextState: infrav1.StateAssociateDevice, reason: "DeviceNotFound"}
 		}
-		return actionError{err: fmt.Errorf("failed to get device: %w", err)}
+		return s.actionForAPIError(err, "GetDevice")
 	}
 
 	// check if cluster and machine tags are properly set
@@ -320,7 +335,7 @@ func (s *Service) actionVerifyAssociate(ctx context.Context) actionResult {
 	s.scope.HivelocityMachine.Spec.ProviderID = nil
 
 	log.Info("Device has been dissociated. Go back to StateAssociateDevice")
-	return actionGoBack{nextState: infrav1.StateAssociateDevice}
+	return actionGoBack{nextState: infrav1.StateAssociateDevice, reason: "DeviceTagsMismatch"}
 }
 
 func hasTimedOut(lastUpdated *metav1.Time, timeout time.Duration) bool {
@@ -376,11 +391,22 @@ func (s *Service) actionVerifyShutdown(ctx context.Context) actionResult {
 
 	// handle powered on state
 
-	// if shutdown has been called in the past two minutes already, do not call it again and wait
-	if provisionCondition != nil && provisionCondition.Reason == infrav1.DeviceShutdownCalledReason && !hasTimedOut(&provisionCondition.LastTransitionTime, 2*time.Minute) {
+	if provisionCondition != nil && provisionCondition.Reason == infrav1.DeviceShutdownCalledReason {
+		// if the device does not shut down gracefully in time, turn the power off.
+		if hasTimedOut(&provisionCondition.LastTransitionTime, s.scope.HivelocityMachine.PowerOffTimeout()) {
+			return s.powerOffDevice(ctx, deviceID)
+		}
 		return actionContinue{delay: 30 * time.Second}
 	}
 
+	// if power off has been called in the past two minutes already, do not call it again and wait
+	if provisionCondition != nil && provisionCondition.Reason == infrav1.DevicePowerOffCalledReason {
+		if !hasTimedOut(&provisionCondition.LastTransitionTime, 2*time.Minute) {
+			return actionContinue{delay: 30 * time.Second}
+		}
+		return s.powerOffDevice(ctx, deviceID)
+	}
+
 	// remove condition to reset the timer - we set the condition anyway again
 	conditions.Delete(s.scope.HivelocityMachine, infrav1.DeviceProvisioningSucceededCondition)
 
@@ -408,11 +434,8 @@ func (s *Service) isReloadingTooLong(condition *clusterv1.Condition, isPowerOn b
 	if condition.Reason != infrav1.DeviceReloadingReason {
 		return false
 	}
-	timeout := 5 * time.Minute
-	if isPowerOn {
-		// the device is "reloading" during provisioning, which can take longer.
-		timeout = 25 * time.Minute
-	}
+	// the device is "reloading" during provisioning, which can take longer.
+	timeout := s.scope.HivelocityMachine.ReloadTimeout(isPowerOn)
 	if !hasTimedOut(&condition.LastTransitionTime, timeout) {
 		return false
 	}
@@ -425,7 +448,6 @@ func (s *Service) isReloadingTooLong(condition *clusterv1.Condition, isPowerOn b
 func (s *Service) setReloadingTooLongTag(ctx context.Context, deviceID int32, lastTransitionTime metav1.Time) actionResult {
 	device, err := s.scope.HVClient.GetDevice(ctx, deviceID)
 	if err != nil {
-		s.handleRateLimitExceeded(err, "GetDevice")
 		if errors.Is(err, hvclient.ErrDeviceNotFound) {
 			msg := fmt.Sprintf("Hivelocity device %d not found", deviceID)
 			conditions.MarkFalse(
@@ -439,17 +461,18 @@ func (s *Service) setReloadingTooLongTag(ctx context.Context, deviceID int32, la
 			s.scope.HivelocityMachine.SetFailure(capierrors.UpdateMachineError, infrav1.FailureMessageDeviceNotFound)
 			return actionComplete{}
 		}
-		return actionError{err: fmt.Errorf("failed to get associated device: %w", err)}
-	}
-	tags := hvtag.RemoveEphemeralTags(device.Tags)
-	_, err = hvtag.PermanentErrorTagFromList(tags)
-	if errors.Is(err, hvtag.ErrDeviceTagNotFound) {
-		tags = append(tags, fmt.Sprintf("%s=reloading-since-%s",
-			hvtag.DeviceTagKeyPermanentError,
-			lastTransitionTime.Format(time.RFC3339)))
-	} else if err != nil {
+		return s.actionForAPIError(err, "GetDevice")
+	}
+	_, err = hvtag.PermanentErrorTagFromList(device.Tags)
+	if err != nil && !errors.Is(err, hvtag.ErrDeviceTagNotFound) {
 		return actionError{err: fmt.Errorf("[setReloadingTooLongTag] PermanentErrorTagFromList failed: %w", err)}
 	}
+	// add the permanent error before the ephemeral tags are removed, so that it records the cluster of the device
+	tags, _ := hvtag.AddPermanentErrorTag(device.Tags, hvtag.DeviceTag{
+		Key:   hvtag.DeviceTagKeyPermanentError,
+		Value: "reloading-since-" + lastTransitionTime.Format(time.RFC3339),
+	})
+	tags = hvtag.RemoveEphemeralTags(tags)
 
 	err = s.scope.HVClient.SetDeviceTags(ctx, device.DeviceId, tags)
 	if err != nil {
@@ -467,7 +490,8 @@ func (s *Service) setReloadingTooLongTag(ctx context.Context, deviceID int32, la
 		msg,
 	)
 	record.Warnf(s.scope.HivelocityMachine, "DeviceReloadingTooLong", msg)
-	return actionGoBack{nextState: infrav1.StateAssociateDevice}
+	metrics.RecordDeviceQuarantined(reloadingTooLongReason)
+	return actionGoBack{nextState: infrav1.StateAssociateDevice, reason: "DeviceReloadingTooLong"}
 }
 
 func (s *Service) getPowerAndReloadingState(ctx context.Context, deviceID int32) (
@@ -504,16 +528,18 @@ func (s *Service) actionProvisionDevice(ctx context.Context) actionResult {
 
 	device, err := s.scope.HVClient.GetDevice(ctx, deviceID)
 	if err != nil {
-		s.handleRateLimitExceeded(err, "GetDevice")
 		if errors.Is(err, hvclient.ErrDeviceNotFound) {
 			// if device cannot be found, we associate a new one
 			log.Info("Device to provision not found. Go back to StateAssociateDevice")
 			record.Warnf(s.scope.HivelocityMachine, "DeviceNotFound", "Hivelocity device not found. Associate new one")
-			return actionGoBack{nextState: infrav1.StateAssociateDevice}
+			return actionGoBack{nextState: infrav1.StateAssociateDevice, reason: "DeviceNotFound"}
 		}
-		return actionError{err: fmt.Errorf("failed to get device: %w", err)}
+		return s.actionForAPIError(err, "GetDevice")
 	}
 
+	// the node has to join the cluster again after provisioning, e.g. after a reprovisioning remediation.
+	conditions.Delete(s.scope.HivelocityMachine, infrav1.NodeJoinedCondition)
+
 	isReloading, isPoweredOn, err := s.getPowerAndReloadingState(ctx, deviceID)
 	if err != nil {
 		return actionError{err: fmt.Errorf("[actionProvisionDevice] getPowerAndReloadingState failed: %s", err)}
@@ -529,19 +555,29 @@ func (s *Service) actionProvisionDevice(ctx context.Context) actionResult {
 		return actionContinue{delay: 4 * time.Second}
 	}
 
+	if result := s.reconcileBond(ctx, deviceID); result != nil {
+		return result
+	}
+
 	userData, err := s.scope.GetRawBootstrapData(ctx)
 	if err != nil {
 		record.Warnf(s.scope.HivelocityMachine, "FailedGetBootstrapData", err.Error())
 		return actionError{err: fmt.Errorf("failed to get raw bootstrap data: %s", err)}
 	}
 
+	userData, err = cloudConfigWithNetworkPolicy(userData, s.scope.HivelocityCluster.Spec.NetworkPolicy)
+	if err != nil {
+		record.Warnf(s.scope.HivelocityMachine, "FailedAddNetworkPolicy", err.Error())
+		return actionError{err: fmt.Errorf("failed to add network policy to bootstrap data: %w", err)}
+	}
+
 	image, err := s.getDeviceImage(ctx)
 	if err != nil {
 		return actionError{err: fmt.Errorf("failed to get device image: %w", err)}
 	}
 
 	opts := hv.BareMetalDeviceUpdate{
-		Hostname:    fmt.Sprintf("%s.example.com", s.scope.Name()), // TODO: HV API requires a FQDN.
+		Hostname:    s.scope.Hostname(),
 		Tags:        device.Tags,
 		Script:      "#cloud-config\n" + string(userData), // cloud-init script
 		OsName:      image,
@@ -573,11 +609,16 @@ func (s *Service) actionProvisionDevice(ctx context.Context) actionResult {
 		opts.PublicSshKeyId = sshKeyID
 	}
 
+	if err := s.applyIPMIWhitelist(ctx, deviceID); err != nil {
+		s.handleRateLimitExceeded(err, "applyIPMIWhitelist")
+		record.Warnf(s.scope.HivelocityMachine, "FailedApplyIPMIWhitelist", "Failed to apply IPMI whitelist to device %d: %s", deviceID, err)
+		return actionError{err: fmt.Errorf("failed to apply IPMI whitelist: %w", err)}
+	}
+
 	// Provision the device
 	if _, err := s.scope.HVClient.ProvisionDevice(ctx, deviceID, opts); err != nil {
-		s.handleRateLimitExceeded(err, "ProvisionDevice")
 		record.Warnf(s.scope.HivelocityMachine, "FailedProvisionDevice", "Failed to provision device %d: %s", deviceID, err)
-		return actionContinue{delay: 30 * time.Second}
+		return s.actionForAPIError(err, "ProvisionDevice")
 	}
 
 	record.Eventf(s.scope.HivelocityMachine, "SuccessfulStartedProvisionDevice", "Successfully started ProvisionDevice: %d", deviceID)
@@ -590,6 +631,44 @@ func (s *Service) actionProvisionDevice(ctx context.Context) actionResult {
 	return actionComplete{}
 }
 
+// applyIPMIWhitelist whitelists the IPs of the cluster for IPMI access to the device. The whitelisted IPs are
+// recorded in the status, so that retries only add the missing IPs.
+func (s *Service) applyIPMIWhitelist(ctx context.Context, deviceID int32) error {
+	hvMachine := s.scope.HivelocityMachine
+	for _, ip := range s.scope.HivelocityCluster.Spec.IPMIWhitelist {
+		if slices.Contains(hvMachine.Status.IPMIWhitelist, ip) {
+			continue
+		}
+		if err := s.scope.HVClient.AddIPMIWhitelistIP(ctx, deviceID, ip); err != nil {
+			return fmt.Errorf("failed to whitelist %q: %w", ip, err)
+		}
+		hvMachine.Status.IPMIWhitelist = append(hvMachine.Status.IPMIWhitelist, ip)
+	}
+	return nil
+}
+
+// clearIPMIWhitelist removes the whitelisted IPs from the device. The Hivelocity API cannot do this, which is reported
+// in a warning, but does not block the release of the device.
+func (s *Service) clearIPMIWhitelist(ctx context.Context, deviceID int32) error {
+	hvMachine := s.scope.HivelocityMachine
+	if len(hvMachine.Status.IPMIWhitelist) == 0 {
+		return nil
+	}
+	err := s.scope.HVClient.ClearIPMIWhitelist(ctx, deviceID)
+	if errors.Is(err, hvclient.ErrIPMIWhitelistNotClearable) {
+		record.Warnf(hvMachine, "IPMIWhitelistNotCleared",
+			"IPs %s stay whitelisted for IPMI access to device %d, remove them in the portal: %s",
+			strings.Join(hvMachine.Status.IPMIWhitelist, ", "), deviceID, err)
+		hvMachine.Status.IPMIWhitelist = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	hvMachine.Status.IPMIWhitelist = nil
+	return nil
+}
+
 func findSSHKey(sshKeysInAPI []hv.SshKeyResponse, sshKeyName string) (int32, error) {
 	for _, key := range sshKeysInAPI {
 		if key.Name == sshKeyName {
@@ -617,7 +696,6 @@ func (s *Service) actionDeviceProvisioned(ctx context.Context) actionResult {
 
 	device, err := s.scope.HVClient.GetDevice(ctx, deviceID)
 	if err != nil {
-		s.handleRateLimitExceeded(err, "GetDevice")
 		if errors.Is(err, hvclient.ErrDeviceNotFound) {
 			// fatal error when device was not found
 			conditions.MarkFalse(
@@ -625,13 +703,13 @@ func (s *Service) actionDeviceProvisioned(ctx context.Context) actionResult {
 				infrav1.DeviceReadyCondition,
 				infrav1.DeviceNotFoundReason,
 				clusterv1.ConditionSeverityError,
-				fmt.Sprintf("device %d not found", device.DeviceId),
+				fmt.Sprintf("device %d not found", deviceID),
 			)
 			record.Warnf(s.scope.HivelocityMachine, "DeviceNotFound", "Hivelocity device not found")
 			s.scope.HivelocityMachine.SetFailure(capierrors.UpdateMachineError, infrav1.FailureMessageDeviceNotFound)
 			return actionComplete{}
 		}
-		return actionError{err: fmt.Errorf("failed to get associated device: %w", err)}
+		return s.actionForAPIError(err, "GetDevice")
 	}
 
 	// verify device
@@ -669,16 +747,31 @@ func (s *Service) actionDeviceProvisioned(ctx context.Context) actionResult {
 		infrav1.DeviceProvisioningSucceededCondition)
 
 	// update machine object with infos from device
+	addresses := s.reconcileDeviceAddresses(ctx, device)
 	conditions.MarkTrue(s.scope.HivelocityMachine, infrav1.DeviceReadyCondition)
-	s.scope.HivelocityMachine.SetMachineStatus(device)
+	s.scope.HivelocityMachine.SetMachineStatus(device, addresses)
+
+	nullRouted := s.reconcileNullRoutes(ctx)
+	s.reconcileHardwareHealth(ctx, deviceID)
+	s.reconcileDeviceEvents(ctx, deviceID)
+
+	if nullRouted && s.scope.HivelocityMachine.Spec.RemediateNullRoutedDevice {
+		// a MachineHealthCheck remediates failed machines
+		s.scope.HivelocityMachine.SetFailure(capierrors.UpdateMachineError, infrav1.FailureMessageDeviceNullRouted)
+		return actionComplete{}
+	}
 	if device.PowerStatus == hvclient.PowerStatusOff {
 		conditions.MarkFalse(s.scope.HivelocityMachine, infrav1.HivelocityMachineReadyCondition, infrav1.DevicePowerOffReason, clusterv1.ConditionSeverityError, "the device is in power off state")
 		s.scope.HivelocityMachine.Status.Ready = false
-		return actionContinue{delay: 20 * time.Second}
+		return s.reconcilePowerOn(ctx, deviceID)
 	}
 	conditions.MarkTrue(s.scope.HivelocityMachine, infrav1.HivelocityMachineReadyCondition)
 	s.scope.HivelocityMachine.Status.Ready = true
 
+	if delay := s.reconcileNode(ctx, device); delay > 0 {
+		return actionContinue{delay: delay}
+	}
+
 	log.V(1).Info("Completed function. This is the final state. The machine is provisioned.",
 		"DeviceId", device.DeviceId,
 		"PowerStatus", device.PowerStatus,
@@ -687,6 +780,39 @@ func (s *Service) actionDeviceProvisioned(ctx context.Context) actionResult {
 	return actionComplete{}
 }
 
+// reconcileNode reconciles the DNS records of the node and checks whether the node joined the workload cluster.
+// DNS records are optional, so that a failure does not hide the check of the node. It returns the shorter
+// delay of both until the next check, or zero if nothing is left to do.
+func (s *Service) reconcileNode(ctx context.Context, device hv.BareMetalDevice) time.Duration {
+	const (
+		dnsRecordsRetryDelay = 30 * time.Second
+		nodeJoinedRetryDelay = 30 * time.Second
+	)
+	var delay time.Duration
+
+	if err := s.reconcileNodeDNSRecords(ctx, device); err != nil {
+		s.handleRateLimitExceeded(err, "reconcileNodeDNSRecords")
+		msg := fmt.Sprintf("failed to reconcile DNS records of device %d: %s", device.DeviceId, err.Error())
+		conditions.MarkFalse(
+			s.scope.HivelocityMachine,
+			infrav1.DNSRecordsReadyCondition,
+			infrav1.DNSRecordsReconcileFailedReason,
+			clusterv1.ConditionSeverityWarning,
+			msg,
+		)
+		record.Warnf(s.scope.HivelocityMachine, "FailedReconcileDNSRecords", msg)
+		delay = dnsRecordsRetryDelay
+	}
+
+	if waiting := s.reconcileNodeJoined(ctx); waiting {
+		if delay == 0 {
+			delay = nodeJoinedRetryDelay
+		}
+		delay = min(delay, nodeJoinedRetryDelay)
+	}
+	return delay
+}
+
 func (s *Service) verifyAssociatedDevice(device *hv.BareMetalDevice) error {
 	deviceTag, err := hvtag.ClusterTagFromList(device.Tags)
 	if err != nil {
@@ -718,14 +844,13 @@ func (s *Service) actionDeleteDeviceDeProvision(ctx context.Context) (ar actionR
 
 	device, err := s.scope.HVClient.GetDevice(ctx, deviceID)
 	if err != nil {
-		s.handleRateLimitExceeded(err, "GetDevice")
 		if errors.Is(err, hvclient.ErrDeviceNotFound) {
 			// Nothing to do if device is not found
 			s.scope.Info("Unable to locate Hivelocity device by ID or tags")
 			record.Warnf(s.scope.HivelocityMachine, "NoDeviceFound", "Unable to find matching Hivelocity device for %s", s.scope.Name())
 			return actionComplete{}
 		}
-		return actionError{err: fmt.Errorf("failed to get device: %w", err)}
+		return s.actionForAPIError(err, "GetDevice")
 	}
 
 	isReloading, isPoweredOn, err := s.getPowerAndReloadingState(ctx, deviceID)
@@ -802,9 +927,8 @@ func (s *Service) actionDeleteDeviceDeProvisionPowerIsOff(ctx context.Context, d
 	// Deprovision the device with default image.
 	if _, err := s.scope.HVClient.ProvisionDevice(ctx, deviceID, opts); err != nil {
 		// TODO: Handle error that machine is not shut down
-		s.handleRateLimitExceeded(err, "ProvisionDevice")
 		record.Warnf(s.scope.HivelocityMachine, "FailedCallProvisionToDeprovision", "Failed to call provision to deprovision device %d: %s", deviceID, err)
-		return actionError{err: fmt.Errorf("failed to de-provision device %d: %s", deviceID, err)}
+		return s.actionForAPIError(err, "ProvisionDevice")
 	}
 	msg := fmt.Sprintf("Successfully called provision to deprovision %d with %s",
 		deviceID, opts.OsName)
@@ -832,7 +956,6 @@ func (s *Service) actionDeleteDeviceDissociate(ctx context.Context) actionResult
 
 	device, err := s.scope.HVClient.GetDevice(ctx, deviceID)
 	if err != nil {
-		s.handleRateLimitExceeded(err, "GetDevice")
 		if errors.Is(err, hvclient.ErrDeviceNotFound) {
 			// Nothing to do if device is not found
 			msg := fmt.Sprintf("[actionDeleteDeviceDissociate] Unable to find matching Hivelocity device %d", deviceID)
@@ -840,7 +963,7 @@ func (s *Service) actionDeleteDeviceDissociate(ctx context.Context) actionResult
 			record.Warnf(s.scope.HivelocityMachine, "NoDeviceFound", msg)
 			return actionComplete{}
 		}
-		return actionError{err: fmt.Errorf("failed to get device: %w", err)}
+		return s.actionForAPIError(err, "GetDevice")
 	}
 
 	if device.PowerStatus != hvclient.PowerStatusOff {
@@ -863,6 +986,21 @@ func (s *Service) actionDeleteDeviceDissociate(ctx context.Context) actionResult
 		s.scope.HivelocityMachine,
 		infrav1.DeviceDeProvisioningSucceededCondition)
 
+	if err := s.deleteNodeDNSRecords(ctx); err != nil {
+		s.handleRateLimitExceeded(err, "deleteNodeDNSRecords")
+		return actionError{err: fmt.Errorf("[actionDeleteDeviceDissociate] failed to delete DNS records: %w", err)}
+	}
+
+	if err := s.clearIPMIWhitelist(ctx, deviceID); err != nil {
+		s.handleRateLimitExceeded(err, "ClearIPMIWhitelist")
+		return actionError{err: fmt.Errorf("[actionDeleteDeviceDissociate] failed to clear IPMI whitelist: %w", err)}
+	}
+
+	if err := s.removeBond(ctx, deviceID); err != nil {
+		s.handleRateLimitExceeded(err, "removeBond")
+		return actionError{err: fmt.Errorf("[actionDeleteDeviceDissociate] failed to remove bond: %w", err)}
+	}
+
 	newTags, updated1 := s.scope.HivelocityCluster.DeviceTag().RemoveFromList(device.Tags)
 	newTags, updated2 := s.scope.HivelocityMachine.DeviceTag().RemoveFromList(newTags)
 	newTags, updated3 := s.scope.DeviceTagMachineType().RemoveFromList(newTags)
@@ -891,3 +1029,23 @@ func (s *Service) handleRateLimitExceeded(err error, functionName string) {
 		record.Warnf(s.scope.HivelocityMachine, "RateLimitExceeded", msg)
 	}
 }
+
+// actionForAPIError returns the result for a failed call of the Hivelocity API, depending on the category of the error.
+// Transient errors, exceeded rate limits and conflicts with the state of the device are retried later.
+// Other errors fail the reconcile.
+func (s *Service) actionForAPIError(err error, functionName string) actionResult {
+	s.handleRateLimitExceeded(err, functionName)
+	switch hvclient.CategoryOf(err) {
+	case hvclient.ErrorCategoryTransient:
+		s.scope.Logger.Info("transient error of Hivelocity API", "function", functionName, "err", err.Error())
+		return actionContinue{delay: 30 * time.Second}
+	case hvclient.ErrorCategoryRateLimit:
+		return actionContinue{delay: time.Minute}
+	case hvclient.ErrorCategoryConflict:
+		// e.g. the device is busy with a reload. The action is possible once the device is done.
+		s.scope.Logger.Info("conflict with state of device", "function", functionName, "err", err.Error())
+		return actionContinue{delay: time.Minute}
+	default:
+		return actionError{err: fmt.Errorf("%s failed: %w", functionName, err)}
+	}
+}
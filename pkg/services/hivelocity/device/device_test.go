@@ -19,10 +19,15 @@ package device
 
 import (
 	"context"
+	"errors"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
 	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
 	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
 	hv "github.com/hivelocity/hivelocity-client-go/client"
@@ -154,3 +159,51 @@ func TestService_verifyAssociatedDevice(t *testing.T) {
 	err = service.verifyAssociatedDevice(&device)
 	require.ErrorIs(t, err, hvtag.ErrDeviceTagNotFound)
 }
+
+func TestService_actionForAPIError(t *testing.T) {
+	hvMachine := &infrav1.HivelocityMachine{ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"}}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard()},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	transientErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	require.Equal(t, actionContinue{delay: 30 * time.Second}, service.actionForAPIError(transientErr, "GetDevice"))
+	require.Equal(t, actionContinue{delay: time.Minute}, service.actionForAPIError(hvclient.ErrRateLimitExceeded, "GetDevice"))
+	require.Equal(t, actionContinue{delay: time.Minute}, service.actionForAPIError(hvclient.ErrDeviceBusy, "ProvisionDevice"))
+
+	actResult := service.actionForAPIError(errors.New("invalid request"), "ProvisionDevice")
+	require.IsType(t, actionError{}, actResult)
+	require.ErrorContains(t, actResult.(actionError).err, "ProvisionDevice failed")
+}
+
+// getDeviceErrorClient fails to get devices.
+type getDeviceErrorClient struct {
+	hvclient.Client
+	err error
+}
+
+func (c *getDeviceErrorClient) GetDevice(context.Context, int32) (hv.BareMetalDevice, error) {
+	return hv.BareMetalDevice{}, c.err
+}
+
+func TestService_setReloadingTooLongTag_apiError(t *testing.T) {
+	hvClient := &getDeviceErrorClient{err: hvclient.ErrRateLimitExceeded}
+	hvMachine := &infrav1.HivelocityMachine{ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"}}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: hvClient},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	require.Equal(t, actionContinue{delay: time.Minute},
+		service.setReloadingTooLongTag(context.Background(), mockclient.FreeDeviceID, metav1.Now()))
+	require.Nil(t, hvMachine.Status.FailureReason)
+
+	hvClient.err = hvclient.ErrDeviceNotFound
+	require.Equal(t, actionComplete{}, service.setReloadingTooLongTag(context.Background(), mockclient.FreeDeviceID, metav1.Now()))
+	require.NotNil(t, hvMachine.Status.FailureReason)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"fmt"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/dns"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// reconcileNodeDNSRecords makes sure that the address records of the node point to its external IPs
+// and that the PTR record of the primary IP matches the hostname of the device.
+// The records get only reconciled until the DNSRecordsReady condition is true.
+func (s *Service) reconcileNodeDNSRecords(ctx context.Context, device hv.BareMetalDevice) error {
+	dnsSpec := s.scope.HivelocityCluster.Spec.DNS
+	if dnsSpec == nil || !dnsSpec.NodeRecords {
+		conditions.Delete(s.scope.HivelocityMachine, infrav1.DNSRecordsReadyCondition)
+		return nil
+	}
+
+	if conditions.IsTrue(s.scope.HivelocityMachine, infrav1.DNSRecordsReadyCondition) {
+		return nil
+	}
+
+	if device.PrimaryIp == "" {
+		return fmt.Errorf("device %d has no primary IP", device.DeviceId)
+	}
+
+	addresses := []string{device.PrimaryIp}
+	for _, address := range s.scope.HivelocityMachine.Status.Addresses {
+		if address.Type == clusterv1.MachineExternalIP {
+			addresses = append(addresses, address.Address)
+		}
+	}
+
+	hostname := s.scope.Hostname()
+	if err := dns.ReconcileAddressRecords(ctx, s.scope.HVClient, dnsSpec.Zone, hostname, dnsSpec.TTL, addresses); err != nil {
+		return err
+	}
+	if err := dns.ReconcilePTRRecord(ctx, s.scope.HVClient, device.PrimaryIp, hostname, dnsSpec.TTL); err != nil {
+		return err
+	}
+
+	conditions.MarkTrue(s.scope.HivelocityMachine, infrav1.DNSRecordsReadyCondition)
+	return nil
+}
+
+// deleteNodeDNSRecords deletes the address records of the node. PTR records can't be deleted with the
+// Hivelocity API. They get overwritten the next time the device gets provisioned.
+func (s *Service) deleteNodeDNSRecords(ctx context.Context) error {
+	dnsSpec := s.scope.HivelocityCluster.Spec.DNS
+	if dnsSpec == nil || !dnsSpec.NodeRecords {
+		return nil
+	}
+	if err := dns.DeleteAddressRecords(ctx, s.scope.HVClient, dnsSpec.Zone, s.scope.Hostname()); err != nil {
+		return err
+	}
+	conditions.Delete(s.scope.HivelocityMachine, infrav1.DNSRecordsReadyCondition)
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+)
+
+// deviceEventsPollInterval is the time between two reads of the events of a device.
+const deviceEventsPollInterval = 5 * time.Minute
+
+// reconcileDeviceEvents mirrors new events of the device in the Hivelocity API as events of the HivelocityMachine and
+// keeps the latest ones in its status. The events are read at most once per deviceEventsPollInterval. Errors are
+// only logged, because they must not block the reconciliation.
+func (s *Service) reconcileDeviceEvents(ctx context.Context, deviceID int32) {
+	hvMachine := s.scope.HivelocityMachine
+	if hvMachine.Status.LastDeviceEventsPoll != nil && !hasTimedOut(hvMachine.Status.LastDeviceEventsPoll, deviceEventsPollInterval) {
+		return
+	}
+	now := metav1.Now()
+	hvMachine.Status.LastDeviceEventsPoll = &now
+
+	events, err := s.scope.HVClient.ListDeviceEvents(ctx, deviceID)
+	if err != nil {
+		s.handleRateLimitExceeded(err, "ListDeviceEvents")
+		s.scope.Error(err, "failed to list device events", "deviceID", deviceID)
+		return
+	}
+
+	newEvents := newDeviceEvents(hvMachine.Status.DeviceEvents, events, associatedSince(hvMachine))
+	for _, event := range newEvents {
+		record.Eventf(hvMachine, "HivelocityDeviceEvent", "Device %d: %s at %s",
+			deviceID, event.Action, event.Time.UTC().Format(time.RFC3339))
+	}
+	hvMachine.Status.DeviceEvents = appendDeviceEvents(hvMachine.Status.DeviceEvents, newEvents)
+}
+
+// associatedSince returns the time since when the device belongs to the machine. Events before that time belong
+// to former users of the device. It is the time when the device got associated, or the creation of the machine
+// if this time is not known.
+func associatedSince(hvMachine *infrav1.HivelocityMachine) metav1.Time {
+	if condition := conditions.Get(hvMachine, infrav1.DeviceAssociateSucceededCondition); condition != nil &&
+		condition.Status == corev1.ConditionTrue {
+		return condition.LastTransitionTime
+	}
+	return hvMachine.CreationTimestamp
+}
+
+// newDeviceEvents returns the events which are newer than the known events and not older than since, oldest
+// first. At most infrav1.MaxDeviceEvents are returned, so that a long history does not flood the events of the
+// machine.
+func newDeviceEvents(known []infrav1.DeviceEvent, events []hv.DeviceEvent, since metav1.Time) []infrav1.DeviceEvent {
+	latest := since
+	atLatest := make(map[string]struct{})
+	for _, event := range known {
+		if latest.Before(&event.Time) {
+			latest = event.Time
+			atLatest = make(map[string]struct{})
+		}
+		if event.Time.Equal(&latest) {
+			atLatest[event.Action] = struct{}{}
+		}
+	}
+
+	var result []infrav1.DeviceEvent
+	for _, event := range events {
+		e := infrav1.DeviceEvent{
+			Action: event.Action,
+			Time:   metav1.NewTime(time.Unix(int64(event.Time), 0)),
+		}
+		if e.Time.Before(&latest) {
+			continue
+		}
+		if _, found := atLatest[e.Action]; found && e.Time.Equal(&latest) {
+			continue
+		}
+		result = append(result, e)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(&result[j].Time)
+	})
+	if len(result) > infrav1.MaxDeviceEvents {
+		result = result[len(result)-infrav1.MaxDeviceEvents:]
+	}
+	return result
+}
+
+// appendDeviceEvents appends the new events to the history and drops the oldest events which exceed
+// infrav1.MaxDeviceEvents.
+func appendDeviceEvents(history, newEvents []infrav1.DeviceEvent) []infrav1.DeviceEvent {
+	if len(newEvents) == 0 {
+		return history
+	}
+	history = append(history, newEvents...)
+	if len(history) > infrav1.MaxDeviceEvents {
+		history = history[len(history)-infrav1.MaxDeviceEvents:]
+	}
+	return history
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func Test_newDeviceEvents(t *testing.T) {
+	known := []infrav1.DeviceEvent{
+		{Action: "Device reload", Time: metav1.NewTime(time.Unix(100, 0))},
+		{Action: "Power off", Time: metav1.NewTime(time.Unix(200, 0))},
+	}
+	events := []hv.DeviceEvent{
+		{Action: "Power on", Time: 300},
+		{Action: "Device reload", Time: 100},
+		{Action: "Power off", Time: 200},
+		{Action: "Power cycle", Time: 200},
+	}
+
+	newEvents := newDeviceEvents(known, events, metav1.Time{})
+	require.Equal(t, []infrav1.DeviceEvent{
+		{Action: "Power cycle", Time: metav1.NewTime(time.Unix(200, 0))},
+		{Action: "Power on", Time: metav1.NewTime(time.Unix(300, 0))},
+	}, newEvents)
+
+	// nothing is new if all events are known
+	require.Empty(t, newDeviceEvents(appendDeviceEvents(known, newEvents), events, metav1.Time{}))
+}
+
+func Test_newDeviceEvents_bounded(t *testing.T) {
+	var events []hv.DeviceEvent
+	for i := 0; i < infrav1.MaxDeviceEvents+5; i++ {
+		events = append(events, hv.DeviceEvent{Action: fmt.Sprintf("event-%d", i), Time: int32(i)})
+	}
+
+	newEvents := newDeviceEvents(nil, events, metav1.Time{})
+	require.Len(t, newEvents, infrav1.MaxDeviceEvents)
+	require.Equal(t, "event-5", newEvents[0].Action)
+
+	history := appendDeviceEvents(newEvents, []infrav1.DeviceEvent{{Action: "latest", Time: metav1.NewTime(time.Unix(100, 0))}})
+	require.Len(t, history, infrav1.MaxDeviceEvents)
+	require.Equal(t, "event-6", history[0].Action)
+	require.Equal(t, "latest", history[len(history)-1].Action)
+}
+
+func Test_newDeviceEvents_since(t *testing.T) {
+	events := []hv.DeviceEvent{
+		{Action: "Device reload", Time: 100},
+		{Action: "Power off", Time: 200},
+		{Action: "Power on", Time: 300},
+	}
+
+	// events of former users of the device are dropped
+	require.Equal(t, []infrav1.DeviceEvent{
+		{Action: "Power off", Time: metav1.NewTime(time.Unix(200, 0))},
+		{Action: "Power on", Time: metav1.NewTime(time.Unix(300, 0))},
+	}, newDeviceEvents(nil, events, metav1.NewTime(time.Unix(200, 0))))
+}
+
+func Test_associatedSince(t *testing.T) {
+	created := metav1.NewTime(time.Unix(100, 0))
+	hvMachine := &infrav1.HivelocityMachine{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}}
+	require.Equal(t, created, associatedSince(hvMachine))
+
+	conditions.MarkTrue(hvMachine, infrav1.DeviceAssociateSucceededCondition)
+	require.Equal(t, conditions.GetLastTransitionTime(hvMachine, infrav1.DeviceAssociateSucceededCondition).Time, associatedSince(hvMachine).Time)
+}
+
+func Test_reconcileDeviceEvents(t *testing.T) {
+	hvMachine := &infrav1.HivelocityMachine{ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"}}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: mockclient.NewMockedHVClientFactory().NewClient("dummy-key")},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	service.reconcileDeviceEvents(context.Background(), mockclient.FreeDeviceID)
+	require.Len(t, hvMachine.Status.DeviceEvents, len(mockclient.DefaultDeviceEvents))
+	require.NotNil(t, hvMachine.Status.LastDeviceEventsPoll)
+
+	// events are not read again within the interval
+	lastPoll := hvMachine.Status.LastDeviceEventsPoll
+	hvMachine.Status.DeviceEvents = nil
+	service.reconcileDeviceEvents(context.Background(), mockclient.FreeDeviceID)
+	require.Equal(t, lastPoll, hvMachine.Status.LastDeviceEventsPoll)
+	require.Empty(t, hvMachine.Status.DeviceEvents)
+
+	// events before the association of the device are dropped
+	hvMachine.Status.LastDeviceEventsPoll = nil
+	hvMachine.Status.DeviceEvents = nil
+	hvMachine.CreationTimestamp = metav1.NewTime(time.Unix(int64(mockclient.DefaultDeviceEvents[1].Time), 0))
+	service.reconcileDeviceEvents(context.Background(), mockclient.FreeDeviceID)
+	require.Len(t, hvMachine.Status.DeviceEvents, 1)
+	require.Equal(t, mockclient.DefaultDeviceEvents[1].Action, hvMachine.Status.DeviceEvents[0].Action)
+	hvMachine.CreationTimestamp = metav1.Time{}
+	hvMachine.Status.DeviceEvents = nil
+
+	// known events are not added again
+	hvMachine.Status.LastDeviceEventsPoll = nil
+	service.reconcileDeviceEvents(context.Background(), mockclient.FreeDeviceID)
+	require.Len(t, hvMachine.Status.DeviceEvents, len(mockclient.DefaultDeviceEvents))
+	hvMachine.Status.LastDeviceEventsPoll = nil
+	service.reconcileDeviceEvents(context.Background(), mockclient.FreeDeviceID)
+	require.Len(t, hvMachine.Status.DeviceEvents, len(mockclient.DefaultDeviceEvents))
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+)
+
+// hardwareCheckInterval is the time between two checks of the IPMI sensors of a device.
+const hardwareCheckInterval = 10 * time.Minute
+
+// reconcileHardwareHealth sets the HardwareHealthy condition based on the IPMI sensors of the device.
+// The sensors are read at most once per hardwareCheckInterval. Errors are reported in the condition only,
+// because they must not block the reconciliation of the machine.
+func (s *Service) reconcileHardwareHealth(ctx context.Context, deviceID int32) {
+	hvMachine := s.scope.HivelocityMachine
+	if hvMachine.Status.LastHardwareCheck != nil && !hasTimedOut(hvMachine.Status.LastHardwareCheck, hardwareCheckInterval) {
+		return
+	}
+	now := metav1.Now()
+	hvMachine.Status.LastHardwareCheck = &now
+
+	sensors, err := s.scope.HVClient.ListDeviceIPMISensors(ctx, deviceID)
+	if err != nil {
+		s.handleRateLimitExceeded(err, "ListDeviceIPMISensors")
+		conditions.MarkUnknown(hvMachine, infrav1.HardwareHealthyCondition, infrav1.IPMISensorsUnavailableReason,
+			"failed to read IPMI sensors: %s", err)
+		return
+	}
+
+	failing := failingSensors(sensors)
+	if len(failing) == 0 {
+		conditions.MarkTrue(hvMachine, infrav1.HardwareHealthyCondition)
+		return
+	}
+
+	descriptions := make([]string, 0, len(failing))
+	for _, sensor := range failing {
+		descriptions = append(descriptions, describeSensor(sensor))
+	}
+	msg := fmt.Sprintf("failing IPMI sensors: %s", strings.Join(descriptions, ", "))
+
+	if conditions.GetReason(hvMachine, infrav1.HardwareHealthyCondition) != infrav1.HardwareSensorsFailingReason {
+		record.Warnf(hvMachine, "HardwareSensorsFailing", msg)
+	}
+	conditions.MarkFalse(
+		hvMachine,
+		infrav1.HardwareHealthyCondition,
+		infrav1.HardwareSensorsFailingReason,
+		clusterv1.ConditionSeverityWarning,
+		msg,
+	)
+}
+
+// failingSensors returns the sensors which explicitly report a failure. Sensors without status are not failing.
+func failingSensors(sensors []hvclient.IPMISensor) []hvclient.IPMISensor {
+	var failing []hvclient.IPMISensor
+	for _, sensor := range sensors {
+		if sensor.IsFailing() {
+			failing = append(failing, sensor)
+		}
+	}
+	return failing
+}
+
+// describeSensor returns a short description of the sensor and its reading, e.g. "FAN1 (Fan): 0 RPM".
+func describeSensor(sensor hvclient.IPMISensor) string {
+	name := sensor.Name
+	if name == "" {
+		name = sensor.SensorID
+	}
+	if sensor.Group != "" {
+		name = fmt.Sprintf("%s (%s)", name, sensor.Group)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s: %g %s", name, sensor.Reading, sensor.Units))
+}
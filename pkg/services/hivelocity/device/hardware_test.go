@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func Test_failingSensors(t *testing.T) {
+	sensors := []hvclient.IPMISensor{
+		{SensorID: "1", Name: "CPU Temp", Group: "Temperature", Units: "degrees C", Reading: 98.5, Status: ptr.To(false)},
+		{SensorID: "2", Name: "FAN1", Group: "Fan", Units: "RPM", Reading: 4200, Status: ptr.To(true)},
+		{SensorID: "3", Units: "discrete", Status: ptr.To(false)},
+		// sensors without status are not failing
+		{SensorID: "4", Name: "Inlet Temp", Group: "Temperature", Units: "degrees C", Reading: 24},
+	}
+	failing := failingSensors(sensors)
+	require.Len(t, failing, 2)
+	require.Equal(t, "CPU Temp (Temperature): 98.5 degrees C", describeSensor(failing[0]))
+	require.Equal(t, "3: 0 discrete", describeSensor(failing[1]))
+
+	require.Empty(t, failingSensors(mockclient.DefaultIPMISensors))
+}
+
+func Test_reconcileHardwareHealth(t *testing.T) {
+	hvMachine := &infrav1.HivelocityMachine{ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"}}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: mockclient.NewMockedHVClientFactory().NewClient("dummy-key")},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	service.reconcileHardwareHealth(context.Background(), mockclient.FreeDeviceID)
+	require.True(t, conditions.IsTrue(hvMachine, infrav1.HardwareHealthyCondition))
+	require.NotNil(t, hvMachine.Status.LastHardwareCheck)
+
+	// sensors are not read again within the interval
+	lastCheck := hvMachine.Status.LastHardwareCheck
+	service.reconcileHardwareHealth(context.Background(), -1)
+	require.Equal(t, lastCheck, hvMachine.Status.LastHardwareCheck)
+	require.True(t, conditions.IsTrue(hvMachine, infrav1.HardwareHealthyCondition))
+
+	// unknown device
+	hvMachine.Status.LastHardwareCheck = nil
+	service.reconcileHardwareHealth(context.Background(), -1)
+	require.Equal(t, corev1.ConditionUnknown, conditions.Get(hvMachine, infrav1.HardwareHealthyCondition).Status)
+}
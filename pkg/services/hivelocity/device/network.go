@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+)
+
+// nullRouteCheckInterval is the time between two checks of the null routes of a device.
+const nullRouteCheckInterval = 2 * time.Minute
+
+// reconcileNullRoutes sets the NetworkReachable condition depending on whether an IP of the machine is null-routed.
+// It returns true if an IP is null-routed. The null routes are checked at most once per nullRouteCheckInterval.
+// Errors are reported in the condition only, because they must not block the reconciliation of the machine.
+func (s *Service) reconcileNullRoutes(ctx context.Context) bool {
+	hvMachine := s.scope.HivelocityMachine
+	if hvMachine.Status.LastNullRouteCheck != nil && !hasTimedOut(hvMachine.Status.LastNullRouteCheck, nullRouteCheckInterval) {
+		return conditions.GetReason(hvMachine, infrav1.NetworkReachableCondition) == infrav1.IPNullRoutedReason
+	}
+	now := metav1.Now()
+	hvMachine.Status.LastNullRouteCheck = &now
+
+	nullRoutes, err := s.scope.HVClient.ListNullRoutes(ctx)
+	if err != nil {
+		s.handleRateLimitExceeded(err, "ListNullRoutes")
+		s.scope.Error(err, "failed to list null routes")
+		conditions.MarkUnknown(hvMachine, infrav1.NetworkReachableCondition, infrav1.NullRoutesUnavailableReason,
+			"failed to list null routes: %s", err)
+		return false
+	}
+
+	routes := nullRoutesOfAddresses(s.scope.HivelocityMachine.Status.Addresses, nullRoutes)
+	if len(routes) == 0 {
+		conditions.MarkTrue(s.scope.HivelocityMachine, infrav1.NetworkReachableCondition)
+		return false
+	}
+
+	descriptions := make([]string, 0, len(routes))
+	for _, route := range routes {
+		description := route.Ip
+		if route.Comment != "" {
+			description = fmt.Sprintf("%s (%s)", route.Ip, route.Comment)
+		}
+		descriptions = append(descriptions, description)
+	}
+	msg := fmt.Sprintf("null-routed IPs: %s", strings.Join(descriptions, ", "))
+
+	if conditions.GetReason(s.scope.HivelocityMachine, infrav1.NetworkReachableCondition) != infrav1.IPNullRoutedReason {
+		record.Warnf(s.scope.HivelocityMachine, "DeviceIPNullRouted", msg)
+	}
+	conditions.MarkFalse(
+		s.scope.HivelocityMachine,
+		infrav1.NetworkReachableCondition,
+		infrav1.IPNullRoutedReason,
+		clusterv1.ConditionSeverityError,
+		msg,
+	)
+	return true
+}
+
+// nullRoutesOfAddresses returns the null routes of the internal and external IPs.
+func nullRoutesOfAddresses(addresses []clusterv1.MachineAddress, nullRoutes []hv.NullRoute) []hv.NullRoute {
+	ips := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		if address.Type != clusterv1.MachineInternalIP && address.Type != clusterv1.MachineExternalIP {
+			continue
+		}
+		if ip := net.ParseIP(address.Address); ip != nil {
+			ips[ip.String()] = struct{}{}
+		}
+	}
+
+	var routes []hv.NullRoute
+	for _, route := range nullRoutes {
+		ip := net.ParseIP(route.Ip)
+		if ip == nil {
+			continue
+		}
+		if _, found := ips[ip.String()]; found {
+			routes = append(routes, route)
+			// avoid duplicates
+			delete(ips, ip.String())
+		}
+	}
+	return routes
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func Test_nullRoutesOfAddresses(t *testing.T) {
+	addresses := []clusterv1.MachineAddress{
+		{Type: clusterv1.MachineHostName, Address: "192.0.2.99"},
+		{Type: clusterv1.MachineInternalIP, Address: "192.0.2.10"},
+		{Type: clusterv1.MachineExternalIP, Address: "192.0.2.10"},
+		{Type: clusterv1.MachineExternalIP, Address: "2001:db8::2"},
+	}
+	nullRoutes := []hv.NullRoute{
+		{Ip: "192.0.2.10", Comment: "DDoS"},
+		{Ip: "192.0.2.11"},
+		{Ip: "192.0.2.99"},
+		{Ip: "2001:0db8::2"},
+	}
+
+	require.Equal(t, []hv.NullRoute{
+		{Ip: "192.0.2.10", Comment: "DDoS"},
+		{Ip: "2001:0db8::2"},
+	}, nullRoutesOfAddresses(addresses, nullRoutes))
+
+	require.Empty(t, nullRoutesOfAddresses(addresses, nil))
+}
+
+// nullRoutesClient returns the null routes or the error and counts the calls.
+type nullRoutesClient struct {
+	hvclient.Client
+	routes []hv.NullRoute
+	err    error
+	calls  int
+}
+
+func (c *nullRoutesClient) ListNullRoutes(context.Context) ([]hv.NullRoute, error) {
+	c.calls++
+	return c.routes, c.err
+}
+
+func Test_reconcileNullRoutes(t *testing.T) {
+	hvClient := &nullRoutesClient{routes: []hv.NullRoute{{Ip: "192.0.2.10"}}}
+	hvMachine := &infrav1.HivelocityMachine{}
+	hvMachine.Status.Addresses = []clusterv1.MachineAddress{{Type: clusterv1.MachineExternalIP, Address: "192.0.2.10"}}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: hvClient},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	require.True(t, service.reconcileNullRoutes(context.Background()))
+	require.Equal(t, infrav1.IPNullRoutedReason, conditions.GetReason(hvMachine, infrav1.NetworkReachableCondition))
+
+	// within the interval, the result of the last check is returned without calling the API
+	require.True(t, service.reconcileNullRoutes(context.Background()))
+	require.Equal(t, 1, hvClient.calls)
+
+	// errors are not fatal
+	hvMachine.Status.LastNullRouteCheck = nil
+	hvClient.err = errors.New("forbidden")
+	require.False(t, service.reconcileNullRoutes(context.Background()))
+	require.Equal(t, corev1.ConditionUnknown, conditions.Get(hvMachine, infrav1.NetworkReachableCondition).Status)
+	require.Equal(t, infrav1.NullRoutesUnavailableReason, conditions.GetReason(hvMachine, infrav1.NetworkReachableCondition))
+}
+
+// ipmiWhitelistClient records the whitelisted IPs.
+type ipmiWhitelistClient struct {
+	hvclient.Client
+	whitelisted []string
+}
+
+func (c *ipmiWhitelistClient) AddIPMIWhitelistIP(_ context.Context, _ int32, ip string) error {
+	c.whitelisted = append(c.whitelisted, ip)
+	return nil
+}
+
+func (c *ipmiWhitelistClient) ClearIPMIWhitelist(context.Context, int32) error {
+	return hvclient.ErrIPMIWhitelistNotClearable
+}
+
+func Test_IPMIWhitelist(t *testing.T) {
+	hvClient := &ipmiWhitelistClient{}
+	hvCluster := &infrav1.HivelocityCluster{}
+	hvCluster.Spec.IPMIWhitelist = []string{"192.0.2.10", "192.0.2.11"}
+	hvMachine := &infrav1.HivelocityMachine{}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: hvClient, HivelocityCluster: hvCluster},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	require.NoError(t, service.applyIPMIWhitelist(context.Background(), 1))
+	require.Equal(t, hvCluster.Spec.IPMIWhitelist, hvMachine.Status.IPMIWhitelist)
+
+	// retries only add the missing IPs
+	hvCluster.Spec.IPMIWhitelist = append(hvCluster.Spec.IPMIWhitelist, "192.0.2.12")
+	require.NoError(t, service.applyIPMIWhitelist(context.Background(), 1))
+	require.Equal(t, []string{"192.0.2.10", "192.0.2.11", "192.0.2.12"}, hvClient.whitelisted)
+
+	// the API cannot clear the whitelist, which does not block the release
+	require.NoError(t, service.clearIPMIWhitelist(context.Background(), 1))
+	require.Empty(t, hvMachine.Status.IPMIWhitelist)
+}
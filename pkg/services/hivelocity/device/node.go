@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+)
+
+// reconcileNodeJoined checks whether the workload cluster has a Node with the providerID of the machine.
+// It returns true if the controller should check again soon. The check stops once the node has joined.
+func (s *Service) reconcileNodeJoined(ctx context.Context) (waiting bool) {
+	hvMachine := s.scope.HivelocityMachine
+	if conditions.IsTrue(hvMachine, infrav1.NodeJoinedCondition) || hvMachine.Spec.ProviderID == nil {
+		return false
+	}
+
+	node, err := s.getNode(ctx, *hvMachine.Spec.ProviderID)
+	if err != nil {
+		// the workload cluster might not be reachable yet, e.g. while the first control plane boots.
+		s.scope.V(1).Info("failed to get node of machine", "error", err.Error())
+	}
+	return s.setNodeJoinedCondition(node)
+}
+
+// errWorkloadClientNotReady gets returned if the target cluster manager of the cluster is not running yet.
+var errWorkloadClientNotReady = errors.New("client of workload cluster is not ready yet")
+
+// getNode returns the Node with the given providerID from the cached client of the workload cluster.
+// It returns nil if there is no such Node.
+func (s *Service) getNode(ctx context.Context, providerID string) (*corev1.Node, error) {
+	if s.scope.WorkloadClient == nil {
+		return nil, errWorkloadClientNotReady
+	}
+	var nodes corev1.NodeList
+	if err := s.scope.WorkloadClient.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return findNodeByProviderID(nodes.Items, providerID), nil
+}
+
+// setNodeJoinedCondition sets the NodeJoined condition. The NodeJoinTimeout starts when the controller starts
+// waiting for the node. It returns true as long as the timeout is not over.
+func (s *Service) setNodeJoinedCondition(node *corev1.Node) (waiting bool) {
+	hvMachine := s.scope.HivelocityMachine
+	if node != nil {
+		conditions.MarkTrue(hvMachine, infrav1.NodeJoinedCondition)
+		return false
+	}
+
+	condition := conditions.Get(hvMachine, infrav1.NodeJoinedCondition)
+	if condition == nil || condition.Reason != infrav1.WaitingForNodeReason {
+		if condition != nil && condition.Reason == infrav1.NodeJoinTimeoutReason {
+			return false
+		}
+		// the message must not change, so that the transition time marks the start of the timeout.
+		conditions.MarkFalse(hvMachine, infrav1.NodeJoinedCondition, infrav1.WaitingForNodeReason,
+			clusterv1.ConditionSeverityInfo, "waiting for the node to join the cluster")
+		return true
+	}
+
+	timeout := hvMachine.NodeJoinTimeout()
+	if !hasTimedOut(&condition.LastTransitionTime, timeout) {
+		return true
+	}
+
+	msg := fmt.Sprintf("no node with providerID %q joined the cluster within %s. Check the cloud-init output of the device",
+		*hvMachine.Spec.ProviderID, timeout)
+	conditions.MarkFalse(hvMachine, infrav1.NodeJoinedCondition, infrav1.NodeJoinTimeoutReason,
+		clusterv1.ConditionSeverityError, msg)
+	record.Warnf(hvMachine, "NodeJoinTimeout", msg)
+	return false
+}
+
+// findNodeByProviderID returns the node with the given providerID or nil.
+func findNodeByProviderID(nodes []corev1.Node, providerID string) *corev1.Node {
+	for i := range nodes {
+		if nodes[i].Spec.ProviderID == providerID {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
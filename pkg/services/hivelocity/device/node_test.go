@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_findNodeByProviderID(t *testing.T) {
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{ProviderID: "hivelocity://1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Spec: corev1.NodeSpec{ProviderID: "hivelocity://2"}},
+	}
+	require.Equal(t, "node-2", findNodeByProviderID(nodes, "hivelocity://2").Name)
+	require.Nil(t, findNodeByProviderID(nodes, "hivelocity://3"))
+}
+
+func Test_getNode(t *testing.T) {
+	service := Service{scope: &scope.MachineScope{ClusterScope: scope.ClusterScope{Logger: logr.Discard()}}}
+
+	// the target cluster manager is not running yet
+	_, err := service.getNode(context.Background(), "hivelocity://1")
+	require.ErrorIs(t, err, errWorkloadClientNotReady)
+
+	service.scope.WorkloadClient = fake.NewClientBuilder().WithObjects(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{ProviderID: "hivelocity://1"},
+	}).Build()
+	node, err := service.getNode(context.Background(), "hivelocity://1")
+	require.NoError(t, err)
+	require.Equal(t, "node-1", node.Name)
+}
+
+func Test_setNodeJoinedCondition(t *testing.T) {
+	providerID := "hivelocity://1"
+	hvMachine := &infrav1.HivelocityMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"},
+		Spec: infrav1.HivelocityMachineSpec{
+			ProviderID:      &providerID,
+			NodeJoinTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard()},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	// start waiting
+	require.True(t, service.setNodeJoinedCondition(nil))
+	require.Equal(t, infrav1.WaitingForNodeReason, conditions.GetReason(hvMachine, infrav1.NodeJoinedCondition))
+
+	// still within the timeout
+	require.True(t, service.setNodeJoinedCondition(nil))
+
+	// timeout is over
+	hvMachine.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-11 * time.Minute))
+	require.False(t, service.setNodeJoinedCondition(nil))
+	require.Equal(t, infrav1.NodeJoinTimeoutReason, conditions.GetReason(hvMachine, infrav1.NodeJoinedCondition))
+
+	// the timeout is kept until the node joins
+	require.False(t, service.setNodeJoinedCondition(nil))
+	require.Equal(t, infrav1.NodeJoinTimeoutReason, conditions.GetReason(hvMachine, infrav1.NodeJoinedCondition))
+
+	require.False(t, service.setNodeJoinedCondition(&corev1.Node{}))
+	require.True(t, conditions.IsTrue(hvMachine, infrav1.NodeJoinedCondition))
+}
+
+func Test_reconcileNode(t *testing.T) {
+	providerID := "hivelocity://1"
+	hvMachine := &infrav1.HivelocityMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"},
+		Spec:       infrav1.HivelocityMachineSpec{ProviderID: &providerID},
+	}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope: scope.ClusterScope{
+				Logger: logr.Discard(),
+				HivelocityCluster: &infrav1.HivelocityCluster{Spec: infrav1.HivelocityClusterSpec{
+					DNS: &infrav1.DNSSpec{Zone: "example.com", NodeRecords: true},
+				}},
+			},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	// the DNS records fail, because the device has no primary IP, but the node is checked anyway
+	require.Equal(t, 30*time.Second, service.reconcileNode(context.Background(), hv.BareMetalDevice{DeviceId: 1}))
+	require.Equal(t, infrav1.DNSRecordsReconcileFailedReason, conditions.GetReason(hvMachine, infrav1.DNSRecordsReadyCondition))
+	require.Equal(t, infrav1.WaitingForNodeReason, conditions.GetReason(hvMachine, infrav1.NodeJoinedCondition))
+
+	// nothing left to do
+	service.scope.HivelocityCluster.Spec.DNS = nil
+	service.scope.WorkloadClient = fake.NewClientBuilder().WithObjects(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+	}).Build()
+	require.Zero(t, service.reconcileNode(context.Background(), hv.BareMetalDevice{DeviceId: 1}))
+	require.True(t, conditions.IsTrue(hvMachine, infrav1.NodeJoinedCondition))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+)
+
+// powerOnWaitTime is the time the device gets to boot before it is turned on again.
+const powerOnWaitTime = 2 * time.Minute
+
+// reconcilePowerOn turns a provisioned device on again which is powered off, depending on the PowerOnPolicy.
+// It expects that the HivelocityMachineReady condition reports the power off state.
+func (s *Service) reconcilePowerOn(ctx context.Context, deviceID int32) actionResult {
+	hvMachine := s.scope.HivelocityMachine
+
+	switch hvMachine.Spec.PowerOnPolicy {
+	case infrav1.PowerOnPolicyAlways:
+	case infrav1.PowerOnPolicyAfterDelay:
+		condition := conditions.Get(hvMachine, infrav1.HivelocityMachineReadyCondition)
+		if condition == nil || condition.Reason != infrav1.DevicePowerOffReason ||
+			!hasTimedOut(&condition.LastTransitionTime, hvMachine.PowerOnDelay()) {
+			return actionContinue{delay: 20 * time.Second}
+		}
+	default:
+		return actionContinue{delay: 20 * time.Second}
+	}
+
+	// give the device time to boot after the last attempt
+	if hvMachine.Status.LastPowerOnAttempt != nil && !hasTimedOut(hvMachine.Status.LastPowerOnAttempt, powerOnWaitTime) {
+		return actionContinue{delay: 20 * time.Second}
+	}
+
+	if err := s.scope.HVClient.PowerOnDevice(ctx, deviceID); err != nil && !errors.Is(err, hvclient.ErrDeviceTurnedOnAlready) {
+		s.handleRateLimitExceeded(err, "PowerOnDevice")
+		record.Warnf(hvMachine, "FailedPowerOnDevice", "Failed to turn on device %d: %s", deviceID, err)
+		return actionError{err: fmt.Errorf("[actionDeviceProvisioned] PowerOnDevice failed: %w", err)}
+	}
+
+	now := metav1.Now()
+	hvMachine.Status.PowerOnAttempts++
+	hvMachine.Status.LastPowerOnAttempt = &now
+	record.Eventf(hvMachine, "PowerOnDevice", "Device %d was powered off. Turned it on (attempt %d, powerOnPolicy %s)",
+		deviceID, hvMachine.Status.PowerOnAttempts, hvMachine.Spec.PowerOnPolicy)
+	return actionContinue{delay: 30 * time.Second}
+}
+
+// powerOffDevice turns the power of the device off, because it did not shut down gracefully.
+func (s *Service) powerOffDevice(ctx context.Context, deviceID int32) actionResult {
+	// remove condition to reset the timer - we set the condition anyway again
+	conditions.Delete(s.scope.HivelocityMachine, infrav1.DeviceProvisioningSucceededCondition)
+
+	err := s.scope.HVClient.PowerOffDevice(ctx, deviceID)
+	if err != nil && !errors.Is(err, hvclient.ErrDeviceShutDownAlready) {
+		s.handleRateLimitExceeded(err, "PowerOffDevice")
+		return actionError{err: fmt.Errorf("[actionVerifyShutdown] PowerOffDevice failed: %w", err)}
+	}
+
+	record.Warnf(s.scope.HivelocityMachine, "DevicePowerOff",
+		"Device %d did not shut down within %s. Called PowerOffDevice API", deviceID, s.scope.HivelocityMachine.PowerOffTimeout())
+
+	conditions.MarkFalse(
+		s.scope.HivelocityMachine,
+		infrav1.DeviceProvisioningSucceededCondition,
+		infrav1.DevicePowerOffCalledReason,
+		clusterv1.ConditionSeverityWarning,
+		"device did not shut down in time and has been powered off",
+	)
+	return actionContinue{delay: 30 * time.Second}
+}
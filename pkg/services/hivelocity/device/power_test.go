@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func Test_actionVerifyShutdown_powerOffTimeout(t *testing.T) {
+	ctx := context.Background()
+	hvClient := mockclient.NewMockedHVClientFactory().NewClient("dummy-key")
+	providerID := fmt.Sprintf("hivelocity://%d", mockclient.FreeDeviceID)
+	hvMachine := &infrav1.HivelocityMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"},
+		Spec: infrav1.HivelocityMachineSpec{
+			ProviderID:      &providerID,
+			PowerOffTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	service := Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: hvClient},
+			HivelocityMachine: hvMachine,
+		},
+	}
+
+	// the mock shuts the device down immediately, so pretend that the shutdown is still pending
+	conditions.MarkFalse(hvMachine, infrav1.DeviceProvisioningSucceededCondition, infrav1.DeviceShutdownCalledReason, "", "")
+	_, ok := service.actionVerifyShutdown(ctx).(actionContinue)
+	require.True(t, ok)
+	require.Equal(t, infrav1.DeviceShutdownCalledReason, conditions.GetReason(hvMachine, infrav1.DeviceProvisioningSucceededCondition))
+
+	// timeout is over
+	hvMachine.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	_, ok = service.actionVerifyShutdown(ctx).(actionContinue)
+	require.True(t, ok)
+	require.Equal(t, infrav1.DevicePowerOffCalledReason, conditions.GetReason(hvMachine, infrav1.DeviceProvisioningSucceededCondition))
+
+	status, err := hvClient.GetDevicePowerStatus(ctx, mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, hvclient.PowerStatusOff, status)
+
+	// device is off now
+	_, ok = service.actionVerifyShutdown(ctx).(actionComplete)
+	require.True(t, ok)
+}
+
+func Test_reconcilePowerOn(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func(policy infrav1.PowerOnPolicy) (Service, hvclient.Client) {
+		hvClient := mockclient.NewMockedHVClientFactory().NewClient("dummy-key")
+		require.NoError(t, hvClient.ShutdownDevice(ctx, mockclient.FreeDeviceID))
+		hvMachine := &infrav1.HivelocityMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "dummy-machine"},
+			Spec:       infrav1.HivelocityMachineSpec{PowerOnPolicy: policy},
+		}
+		conditions.MarkFalse(hvMachine, infrav1.HivelocityMachineReadyCondition, infrav1.DevicePowerOffReason, "", "")
+		return Service{
+			scope: &scope.MachineScope{
+				ClusterScope:      scope.ClusterScope{Logger: logr.Discard(), HVClient: hvClient},
+				HivelocityMachine: hvMachine,
+			},
+		}, hvClient
+	}
+
+	powerStatus := func(hvClient hvclient.Client) string {
+		status, err := hvClient.GetDevicePowerStatus(ctx, mockclient.FreeDeviceID)
+		require.NoError(t, err)
+		return status
+	}
+
+	// Never
+	service, hvClient := newService(infrav1.PowerOnPolicyNever)
+	_, ok := service.reconcilePowerOn(ctx, mockclient.FreeDeviceID).(actionContinue)
+	require.True(t, ok)
+	require.Equal(t, hvclient.PowerStatusOff, powerStatus(hvClient))
+	require.Equal(t, int32(0), service.scope.HivelocityMachine.Status.PowerOnAttempts)
+
+	// Always
+	service, hvClient = newService(infrav1.PowerOnPolicyAlways)
+	_, ok = service.reconcilePowerOn(ctx, mockclient.FreeDeviceID).(actionContinue)
+	require.True(t, ok)
+	require.Equal(t, hvclient.PowerStatusOn, powerStatus(hvClient))
+	require.Equal(t, int32(1), service.scope.HivelocityMachine.Status.PowerOnAttempts)
+	require.NotNil(t, service.scope.HivelocityMachine.Status.LastPowerOnAttempt)
+
+	// no second attempt while the device boots
+	require.NoError(t, hvClient.ShutdownDevice(ctx, mockclient.FreeDeviceID))
+	service.reconcilePowerOn(ctx, mockclient.FreeDeviceID)
+	require.Equal(t, hvclient.PowerStatusOff, powerStatus(hvClient))
+	require.Equal(t, int32(1), service.scope.HivelocityMachine.Status.PowerOnAttempts)
+
+	// AfterDelay
+	service, hvClient = newService(infrav1.PowerOnPolicyAfterDelay)
+	service.reconcilePowerOn(ctx, mockclient.FreeDeviceID)
+	require.Equal(t, hvclient.PowerStatusOff, powerStatus(hvClient))
+
+	service.scope.HivelocityMachine.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-infrav1.DefaultPowerOnDelay - time.Minute))
+	service.reconcilePowerOn(ctx, mockclient.FreeDeviceID)
+	require.Equal(t, hvclient.PowerStatusOn, powerStatus(hvClient))
+	require.Equal(t, int32(1), service.scope.HivelocityMachine.Status.PowerOnAttempts)
+}
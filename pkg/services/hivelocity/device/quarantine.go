@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// ErrDeviceNotQuarantined gets returned if the device has no permanent error tag.
+	ErrDeviceNotQuarantined = errors.New("device has no permanent error tag")
+
+	// ErrDeviceUnhealthy gets returned if the health check of a quarantined device failed.
+	ErrDeviceUnhealthy = errors.New("device health check failed")
+
+	// ErrDeviceQuarantinedByOtherCluster gets returned if the permanent error tag of the device was set by another cluster.
+	ErrDeviceQuarantinedByOtherCluster = errors.New("device was quarantined by another cluster")
+)
+
+// ListQuarantinedDevices returns the devices which may be used by CAPHV, but have the permanent error tag set by the
+// cluster. Devices quarantined by other clusters are left out, see quarantinedBy.
+func ListQuarantinedDevices(ctx context.Context, hvClient hvclient.Client, clusterName string) ([]infrav1.QuarantinedDevice, error) {
+	devices, err := hvClient.ListDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	var quarantined []infrav1.QuarantinedDevice
+	for _, device := range devices {
+		if !hvtag.DeviceUsableByCAPI(device.Tags) || !quarantinedBy(device.Tags, clusterName) {
+			continue
+		}
+		tag, err := hvtag.PermanentErrorTagFromList(device.Tags)
+		if err != nil {
+			continue
+		}
+		reason, since := hvtag.ParsePermanentError(tag.Value)
+		quarantinedDevice := infrav1.QuarantinedDevice{
+			DeviceID: device.DeviceId,
+			Reason:   reason,
+		}
+		if !since.IsZero() {
+			quarantinedDevice.Since = &metav1.Time{Time: since}
+		}
+		if ticketID, err := ticketIDFromTags(device.Tags); err == nil {
+			quarantinedDevice.TicketID = ticketID
+		}
+		quarantined = append(quarantined, quarantinedDevice)
+	}
+	return quarantined, nil
+}
+
+// CheckDeviceHealth returns ErrDeviceUnhealthy if the device is still reloading or if IPMI sensors report a failure.
+func CheckDeviceHealth(ctx context.Context, hvClient hvclient.Client, deviceID int32) error {
+	dump, err := hvClient.GetDeviceDump(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to get device dump of device %d: %w", deviceID, err)
+	}
+	if dump.IsReload {
+		return fmt.Errorf("device %d is reloading: %w", deviceID, ErrDeviceUnhealthy)
+	}
+
+	sensors, err := hvClient.ListDeviceIPMISensors(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to read IPMI sensors of device %d: %w", deviceID, err)
+	}
+	if failing := failingSensors(sensors); len(failing) > 0 {
+		descriptions := make([]string, 0, len(failing))
+		for _, sensor := range failing {
+			descriptions = append(descriptions, describeSensor(sensor))
+		}
+		return fmt.Errorf("device %d has failing IPMI sensors: %s: %w", deviceID, strings.Join(descriptions, ", "),
+			ErrDeviceUnhealthy)
+	}
+	return nil
+}
+
+// ClearPermanentError removes the permanent error tag of the device if it was set by the cluster and the device passes
+// the health check. Afterwards the device can be used by CAPHV again.
+func ClearPermanentError(ctx context.Context, hvClient hvclient.Client, clusterName string, deviceID int32) error {
+	device, err := hvClient.GetDevice(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to get device %d: %w", deviceID, err)
+	}
+	tags, updated := hvtag.RemovePermanentErrorTag(device.Tags)
+	if !updated {
+		return fmt.Errorf("device %d: %w", deviceID, ErrDeviceNotQuarantined)
+	}
+	if !quarantinedBy(device.Tags, clusterName) {
+		return fmt.Errorf("device %d: %w", deviceID, ErrDeviceQuarantinedByOtherCluster)
+	}
+
+	if err := CheckDeviceHealth(ctx, hvClient, deviceID); err != nil {
+		return err
+	}
+
+	if err := hvClient.SetDeviceTags(ctx, deviceID, tags); err != nil {
+		return fmt.Errorf("failed to remove permanent error tag of device %d: %w", deviceID, err)
+	}
+	return nil
+}
+
+// quarantinedBy returns true if the quarantined-by tag of the device names the cluster. Devices which were quarantined
+// before the quarantined-by tag existed have no such tag. For them the cluster tag of the device is used instead.
+func quarantinedBy(tags []string, clusterName string) bool {
+	tag, err := hvtag.QuarantinedByTagFromList(tags)
+	if errors.Is(err, hvtag.ErrDeviceTagNotFound) {
+		tag, err = hvtag.ClusterTagFromList(tags)
+	}
+	return err == nil && tag.Value == clusterName
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ClearPermanentError(t *testing.T) {
+	ctx := context.Background()
+	hvClient := mockclient.NewMockedHVClientFactory().NewClient("dummy-key")
+
+	err := ClearPermanentError(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.ErrorIs(t, err, ErrDeviceNotQuarantined)
+
+	since := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	quarantineDevice(t, hvClient, mockclient.FreeDeviceID, "my-cluster", since)
+
+	quarantined, err := ListQuarantinedDevices(ctx, hvClient, "my-cluster")
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, int32(mockclient.FreeDeviceID), quarantined[0].DeviceID)
+	require.Equal(t, "remediation-failed", quarantined[0].Reason)
+	require.True(t, quarantined[0].Since.Time.Equal(since))
+
+	// other clusters neither list the device nor clear its permanent error
+	quarantined, err = ListQuarantinedDevices(ctx, hvClient, "other-cluster")
+	require.NoError(t, err)
+	require.Empty(t, quarantined)
+	err = ClearPermanentError(ctx, hvClient, "other-cluster", mockclient.FreeDeviceID)
+	require.ErrorIs(t, err, ErrDeviceQuarantinedByOtherCluster)
+
+	require.NoError(t, ClearPermanentError(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID))
+	quarantined, err = ListQuarantinedDevices(ctx, hvClient, "my-cluster")
+	require.NoError(t, err)
+	require.Empty(t, quarantined)
+}
+
+func Test_ClearPermanentError_withoutQuarantinedByTag(t *testing.T) {
+	ctx := context.Background()
+	hvClient := mockclient.NewMockedHVClientFactory().NewClient("dummy-key")
+
+	// a device which was quarantined before the quarantined-by tag existed
+	permanentErrorTag := hvtag.DeviceTag{Key: hvtag.DeviceTagKeyPermanentError, Value: "reloading-since-2023-01-02T15:04:05Z"}
+	require.NoError(t, hvClient.SetDeviceTags(ctx, mockclient.FreeDeviceID, []string{
+		"caphv-use=allow",
+		hvtag.DeviceTag{Key: hvtag.DeviceTagKeyCluster, Value: "my-cluster"}.ToString(),
+		hvtag.DeviceTag{Key: hvtag.DeviceTagKeyMachine, Value: "my-machine"}.ToString(),
+		permanentErrorTag.ToString(),
+	}))
+
+	quarantined, err := ListQuarantinedDevices(ctx, hvClient, "my-cluster")
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, "reloading-since", quarantined[0].Reason)
+
+	quarantined, err = ListQuarantinedDevices(ctx, hvClient, "other-cluster")
+	require.NoError(t, err)
+	require.Empty(t, quarantined)
+	err = ClearPermanentError(ctx, hvClient, "other-cluster", mockclient.FreeDeviceID)
+	require.ErrorIs(t, err, ErrDeviceQuarantinedByOtherCluster)
+
+	require.NoError(t, ClearPermanentError(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID))
+	device, err := hvClient.GetDevice(ctx, mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.NotContains(t, device.Tags, permanentErrorTag.ToString())
+}
+
+// quarantineDevice sets the permanent error tag on the device like the cluster with the name would do it.
+func quarantineDevice(t *testing.T, hvClient hvclient.Client, deviceID int32, clusterName string, since time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	device, err := hvClient.GetDevice(ctx, deviceID)
+	require.NoError(t, err)
+	clusterTag := hvtag.DeviceTag{Key: hvtag.DeviceTagKeyCluster, Value: clusterName}
+	tags, _ := hvtag.AddPermanentErrorTag(append(device.Tags, clusterTag.ToString()), hvtag.PermanentErrorTag("remediation-failed", since))
+	require.NoError(t, hvClient.SetDeviceTags(ctx, deviceID, hvtag.RemoveEphemeralTags(tags)))
+}
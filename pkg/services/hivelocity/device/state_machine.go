@@ -23,6 +23,10 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/cluster-api/util/record"
 )
@@ -47,6 +51,30 @@ func newStateMachine(hvMachine *infrav1.HivelocityMachine, reconciler *Service)
 	return &r
 }
 
+// recordStateChange records the time spent in the old state and, once the device is provisioned,
+// the time since provisioning started.
+func (sm *stateMachine) recordStateChange(oldState, newState infrav1.ProvisioningState) {
+	now := metav1.Now()
+	status := &sm.hvMachine.Spec.Status
+
+	// machines which were created by an older version have no time stamps.
+	if status.StateChangedAt != nil {
+		metrics.RecordProvisioningStateChange(string(oldState), now.Sub(status.StateChangedAt.Time))
+	}
+	status.StateChangedAt = &now
+
+	if newState == infrav1.StateDeviceProvisioned && status.ProvisioningStartedAt != nil {
+		metrics.RecordProvisioned(now.Sub(status.ProvisioningStartedAt.Time))
+		status.ProvisioningStartedAt = nil
+	}
+}
+
+// goBack changes to the previous state requested by the action and records the reason.
+func (sm *stateMachine) goBack(actResult actionGoBack) {
+	metrics.RecordProvisioningGoBack(string(sm.hvMachine.Spec.Status.ProvisioningState), actResult.reason)
+	sm.nextState = actResult.nextState
+}
+
 type stateHandler func(context.Context) actionResult
 
 func (sm *stateMachine) handlers() map[infrav1.ProvisioningState]stateHandler {
@@ -66,6 +94,7 @@ func (sm *stateMachine) ReconcileState(ctx context.Context) (actionRes actionRes
 	defer func() {
 		if sm.nextState != initialState.ProvisioningState {
 			sm.log.Info("changing provisioning state", "old", initialState.ProvisioningState, "new", sm.nextState)
+			sm.recordStateChange(initialState.ProvisioningState, sm.nextState)
 			sm.hvMachine.Spec.Status.ProvisioningState = sm.nextState
 		}
 		if diff := cmp.Diff(initialState, sm.hvMachine.Spec.Status); diff != "" {
@@ -79,18 +108,42 @@ func (sm *stateMachine) ReconcileState(ctx context.Context) (actionRes actionRes
 	if initialState.ProvisioningState == infrav1.StateNone {
 		initialState.ProvisioningState = infrav1.StateAssociateDevice
 		sm.hvMachine.Spec.Status.ProvisioningState = infrav1.StateAssociateDevice
+		now := metav1.Now()
+		sm.hvMachine.Spec.Status.StateChangedAt = &now
+		sm.hvMachine.Spec.Status.ProvisioningStartedAt = &now
 	}
 
 	sm.log.V(1).Info("ReconcileState", "initialState.ProvisioningState", initialState.ProvisioningState)
 
 	if stateHandler, found := sm.handlers()[initialState.ProvisioningState]; found {
-		return stateHandler(ctx)
+		return sm.runStateHandler(ctx, initialState.ProvisioningState, stateHandler)
 	}
 
 	record.Warnf(sm.hvMachine, "NoHandlerFoundForState", "no handler found for state %q", initialState.ProvisioningState)
 	return actionError{err: fmt.Errorf("no handler found for state %q", initialState.ProvisioningState)}
 }
 
+// runStateHandler calls the handler of the state in a span with the state and the device ID as attributes.
+func (sm *stateMachine) runStateHandler(ctx context.Context, state infrav1.ProvisioningState, handler stateHandler) actionResult {
+	ctx, span := tracing.Tracer().Start(ctx, "stateMachine."+string(state), trace.WithAttributes(tracing.ProvisioningState(state)))
+	if deviceID, err := sm.hvMachine.DeviceIDFromProviderID(); err == nil {
+		span.SetAttributes(tracing.DeviceID(deviceID))
+	}
+
+	actResult := handler(ctx)
+
+	span.SetAttributes(
+		attribute.String("caphv.action_result", fmt.Sprintf("%T", actResult)),
+		attribute.String("caphv.next_provisioning_state", string(sm.nextState)),
+	)
+	var err error
+	if actErr, ok := actResult.(actionError); ok {
+		err = actErr.err
+	}
+	tracing.End(span, err)
+	return actResult
+}
+
 func (sm *stateMachine) handleAssociateDevice(ctx context.Context) actionResult {
 	actResult := sm.reconciler.actionAssociateDevice(ctx)
 	if _, ok := actResult.(actionComplete); ok {
@@ -106,9 +159,8 @@ func (sm *stateMachine) handleVerifyAssociate(ctx context.Context) actionResult
 	}
 
 	// check whether we need to associate the machine to another device
-	actionGoBack, ok := actResult.(actionGoBack)
-	if ok {
-		sm.nextState = actionGoBack.nextState
+	if goBack, ok := actResult.(actionGoBack); ok {
+		sm.goBack(goBack)
 	}
 	return actResult
 }
@@ -120,9 +172,8 @@ func (sm *stateMachine) handleVerifyShutdown(ctx context.Context) actionResult {
 	}
 
 	// check whether we need to associate the machine to another device
-	actionGoBack, ok := actResult.(actionGoBack)
-	if ok {
-		sm.nextState = actionGoBack.nextState
+	if goBack, ok := actResult.(actionGoBack); ok {
+		sm.goBack(goBack)
 	}
 	return actResult
 }
@@ -134,9 +185,8 @@ func (sm *stateMachine) handleProvisionDevice(ctx context.Context) actionResult
 	}
 
 	// check whether we need to go back to previous state
-	actionGoBack, ok := actResult.(actionGoBack)
-	if ok {
-		sm.nextState = actionGoBack.nextState
+	if goBack, ok := actResult.(actionGoBack); ok {
+		sm.goBack(goBack)
 	}
 	return actResult
 }
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/tracing"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestStateMachine(state infrav1.ProvisioningState) *stateMachine {
+	hvMachine := &infrav1.HivelocityMachine{}
+	hvMachine.Spec.Status.ProvisioningState = state
+	service := &Service{
+		scope: &scope.MachineScope{
+			ClusterScope:      scope.ClusterScope{Logger: logr.Discard()},
+			HivelocityMachine: hvMachine,
+		},
+	}
+	return newStateMachine(hvMachine, service)
+}
+
+func Test_recordStateChange(t *testing.T) {
+	sm := newTestStateMachine(infrav1.StateProvisionDevice)
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	changed := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	sm.hvMachine.Spec.Status.ProvisioningStartedAt = &started
+	sm.hvMachine.Spec.Status.StateChangedAt = &changed
+
+	sm.recordStateChange(infrav1.StateProvisionDevice, infrav1.StateDeviceProvisioned)
+
+	require.True(t, sm.hvMachine.Spec.Status.StateChangedAt.After(changed.Time))
+	require.Nil(t, sm.hvMachine.Spec.Status.ProvisioningStartedAt)
+}
+
+func Test_recordStateChange_withoutTimestamps(t *testing.T) {
+	sm := newTestStateMachine(infrav1.StateVerifyShutdown)
+
+	sm.recordStateChange(infrav1.StateVerifyShutdown, infrav1.StateProvisionDevice)
+
+	require.NotNil(t, sm.hvMachine.Spec.Status.StateChangedAt)
+	require.Nil(t, sm.hvMachine.Spec.Status.ProvisioningStartedAt)
+}
+
+func Test_goBack(t *testing.T) {
+	sm := newTestStateMachine(infrav1.StateVerifyAssociate)
+	counter := metrics.ProvisioningGoBacks.WithLabelValues(string(infrav1.StateVerifyAssociate), "DeviceNotFound")
+
+	var before dto.Metric
+	require.NoError(t, counter.Write(&before))
+
+	sm.goBack(actionGoBack{nextState: infrav1.StateAssociateDevice, reason: "DeviceNotFound"})
+
+	var after dto.Metric
+	require.NoError(t, counter.Write(&after))
+	require.Equal(t, infrav1.StateAssociateDevice, sm.nextState)
+	require.Equal(t, before.Counter.GetValue()+1, after.Counter.GetValue())
+}
+
+func Test_runStateHandler(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	sm := newTestStateMachine(infrav1.StateProvisionDevice)
+	sm.hvMachine.SetProviderID(7)
+
+	actResult := sm.runStateHandler(context.Background(), infrav1.StateProvisionDevice, func(context.Context) actionResult {
+		return actionError{err: errors.New("provisioning failed")}
+	})
+	require.IsType(t, actionError{}, actResult)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "stateMachine.provision-device", spans[0].Name)
+	require.Contains(t, spans[0].Attributes, tracing.ProvisioningState(infrav1.StateProvisionDevice))
+	require.Contains(t, spans[0].Attributes, tracing.DeviceID(7))
+	require.Contains(t, spans[0].Attributes, attribute.String("caphv.action_result", "device.actionError"))
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+)
+
+// maxTicketEvents is the number of recent device events in a support ticket.
+const maxTicketEvents = 10
+
+// SupportTicketState is the result of ReconcileSupportTicket.
+type SupportTicketState string
+
+const (
+	// SupportTicketOpened means that a support ticket was opened for the device.
+	SupportTicketOpened SupportTicketState = "Opened"
+
+	// SupportTicketPending means that the support ticket of the device is not resolved yet.
+	SupportTicketPending SupportTicketState = "Pending"
+
+	// SupportTicketResolved means that the support ticket was resolved and the permanent error was cleared.
+	SupportTicketResolved SupportTicketState = "Resolved"
+
+	// SupportTicketUnhealthy means that the support ticket was resolved, but the device failed the health check.
+	// The ticket tag was removed, so that a follow-up ticket gets opened.
+	SupportTicketUnhealthy SupportTicketState = "Unhealthy"
+)
+
+// ReconcileSupportTicket opens a support ticket for a device which the cluster quarantined and stores its ID in the
+// ticket tag. No ticket is opened if the device has a ticket tag or an unresolved ticket already.
+// Once the ticket is resolved, the permanent error of the device gets cleared if the device passes the health check.
+// Otherwise the ticket tag is removed and SupportTicketUnhealthy is returned together with the health check error,
+// so that the next call opens a follow-up ticket.
+func ReconcileSupportTicket(ctx context.Context, hvClient hvclient.Client, clusterName string, deviceID int32) (
+	state SupportTicketState, ticketID int32, err error,
+) {
+	device, err := hvClient.GetDevice(ctx, deviceID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get device %d: %w", deviceID, err)
+	}
+	errorTag, err := hvtag.PermanentErrorTagFromList(device.Tags)
+	if err != nil {
+		return "", 0, fmt.Errorf("device %d: %w", deviceID, ErrDeviceNotQuarantined)
+	}
+	if !quarantinedBy(device.Tags, clusterName) {
+		return "", 0, fmt.Errorf("device %d: %w", deviceID, ErrDeviceQuarantinedByOtherCluster)
+	}
+
+	ticketID, err = ticketIDFromTags(device.Tags)
+	if errors.Is(err, hvtag.ErrDeviceTagNotFound) {
+		// the ticket tag may be missing although a ticket was opened, e.g. if setting the tag failed
+		ticketID, err = findSupportTicket(ctx, hvClient, deviceID)
+		if err != nil {
+			return "", 0, err
+		}
+		if ticketID != 0 {
+			if err := setTicketTag(ctx, hvClient, device, ticketID); err != nil {
+				return "", 0, err
+			}
+			return SupportTicketPending, ticketID, nil
+		}
+
+		ticketID, err = openSupportTicket(ctx, hvClient, device, errorTag)
+		if err != nil {
+			return "", 0, err
+		}
+		return SupportTicketOpened, ticketID, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid ticket tag of device %d: %w", deviceID, err)
+	}
+
+	ticket, err := hvClient.GetTicket(ctx, ticketID)
+	if err != nil {
+		return "", ticketID, fmt.Errorf("failed to get ticket %d of device %d: %w", ticketID, deviceID, err)
+	}
+	if !hvclient.IsTicketResolved(ticket) {
+		return SupportTicketPending, ticketID, nil
+	}
+
+	err = ClearPermanentError(ctx, hvClient, clusterName, deviceID)
+	if errors.Is(err, ErrDeviceUnhealthy) {
+		if err := removeTicketTag(ctx, hvClient, device); err != nil {
+			return "", ticketID, err
+		}
+		return SupportTicketUnhealthy, ticketID, fmt.Errorf("ticket %d was resolved: %w", ticketID, err)
+	}
+	if err != nil {
+		return "", ticketID, fmt.Errorf("ticket %d was resolved: %w", ticketID, err)
+	}
+	return SupportTicketResolved, ticketID, nil
+}
+
+// openSupportTicket files a ticket with the reason of the permanent error and the recent events of the device.
+func openSupportTicket(ctx context.Context, hvClient hvclient.Client, device hv.BareMetalDevice, errorTag hvtag.DeviceTag) (int32, error) {
+	events, err := hvClient.ListDeviceEvents(ctx, device.DeviceId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list events of device %d: %w", device.DeviceId, err)
+	}
+
+	ticketID, err := hvClient.CreateTicket(ctx, supportTicketSubject(device.DeviceId), supportTicketBody(device, errorTag, events))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create ticket for device %d: %w", device.DeviceId, err)
+	}
+
+	if err := setTicketTag(ctx, hvClient, device, ticketID); err != nil {
+		return 0, err
+	}
+	return ticketID, nil
+}
+
+// findSupportTicket returns the ID of an unresolved support ticket of the device, or zero if there is none.
+func findSupportTicket(ctx context.Context, hvClient hvclient.Client, deviceID int32) (int32, error) {
+	tickets, err := hvClient.ListTickets(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tickets: %w", err)
+	}
+	subject := supportTicketSubject(deviceID)
+	for _, ticket := range tickets {
+		if ticket.Subject == subject && !hvclient.IsTicketResolved(ticket) {
+			return int32(ticket.Id), nil
+		}
+	}
+	return 0, nil
+}
+
+// setTicketTag stores the ID of the support ticket in the ticket tag of the device.
+func setTicketTag(ctx context.Context, hvClient hvclient.Client, device hv.BareMetalDevice, ticketID int32) error {
+	tag := hvtag.DeviceTag{Key: hvtag.DeviceTagKeyTicketID, Value: strconv.Itoa(int(ticketID))}
+	if err := hvClient.SetDeviceTags(ctx, device.DeviceId, append(device.Tags, tag.ToString())); err != nil {
+		return fmt.Errorf("failed to set ticket tag of device %d: %w", device.DeviceId, err)
+	}
+	return nil
+}
+
+// removeTicketTag removes the ticket tag of the device.
+func removeTicketTag(ctx context.Context, hvClient hvclient.Client, device hv.BareMetalDevice) error {
+	tags := make([]string, 0, len(device.Tags))
+	for _, tag := range device.Tags {
+		if !strings.HasPrefix(tag, hvtag.DeviceTagKeyTicketID.Prefix()) {
+			tags = append(tags, tag)
+		}
+	}
+	if err := hvClient.SetDeviceTags(ctx, device.DeviceId, tags); err != nil {
+		return fmt.Errorf("failed to remove ticket tag of device %d: %w", device.DeviceId, err)
+	}
+	return nil
+}
+
+// supportTicketSubject returns the subject of the support ticket of a quarantined device. It identifies the ticket of
+// the device if the ticket tag is missing.
+func supportTicketSubject(deviceID int32) string {
+	return fmt.Sprintf("Device %d needs a hardware check", deviceID)
+}
+
+// supportTicketBody returns the body of the support ticket of a quarantined device.
+func supportTicketBody(device hv.BareMetalDevice, errorTag hvtag.DeviceTag, events []hv.DeviceEvent) string {
+	reason, since := hvtag.ParsePermanentError(errorTag.Value)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Device %d (%s) was taken out of service by the Cluster API Provider Hivelocity.\n\n", device.DeviceId, device.Hostname)
+	fmt.Fprintf(&b, "Reason: %s\n", reason)
+	if !since.IsZero() {
+		fmt.Fprintf(&b, "Since: %s\n", since.UTC().Format(time.RFC3339))
+	}
+
+	if len(events) > 0 {
+		sorted := append([]hv.DeviceEvent(nil), events...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+		if len(sorted) > maxTicketEvents {
+			sorted = sorted[len(sorted)-maxTicketEvents:]
+		}
+		b.WriteString("\nRecent events:\n")
+		for _, event := range sorted {
+			fmt.Fprintf(&b, "- %s %s\n", time.Unix(int64(event.Time), 0).UTC().Format(time.RFC3339), event.Action)
+		}
+	}
+
+	b.WriteString("\nPlease check the hardware of the device and resolve this ticket once it is repaired. ")
+	b.WriteString("The device will be used again afterwards.\n")
+	return b.String()
+}
+
+// ticketIDFromTags returns the ID in the ticket tag.
+func ticketIDFromTags(tags []string) (int32, error) {
+	tag, err := hvtag.DeviceTagFromList(hvtag.DeviceTagKeyTicketID, tags)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(tag.Value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ticket id %q: %w", tag.Value, err)
+	}
+	return int32(id), nil
+}
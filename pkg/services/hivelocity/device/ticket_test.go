@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+)
+
+func Test_ReconcileSupportTicket(t *testing.T) {
+	ctx := context.Background()
+	hvClient := mockclient.NewMockedHVClientFactory().NewClient("dummy-key")
+
+	_, _, err := ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.ErrorIs(t, err, ErrDeviceNotQuarantined)
+
+	quarantineDevice(t, hvClient, mockclient.FreeDeviceID, "my-cluster", time.Now())
+
+	state, ticketID, err := ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, SupportTicketOpened, state)
+
+	ticket, err := hvClient.GetTicket(ctx, ticketID)
+	require.NoError(t, err)
+	require.Contains(t, ticket.Body, "Reason: remediation-failed")
+	require.Contains(t, ticket.Body, mockclient.DefaultDeviceEvents[0].Action)
+
+	quarantined, err := ListQuarantinedDevices(ctx, hvClient, "my-cluster")
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, ticketID, quarantined[0].TicketID)
+
+	// other clusters don't handle the ticket
+	_, _, err = ReconcileSupportTicket(ctx, hvClient, "other-cluster", mockclient.FreeDeviceID)
+	require.ErrorIs(t, err, ErrDeviceQuarantinedByOtherCluster)
+
+	state, _, err = ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, SupportTicketPending, state)
+
+	mockclient.SetTicketStatus(hvClient, ticketID, "Closed")
+	state, _, err = ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, SupportTicketResolved, state)
+
+	device, err := hvClient.GetDevice(ctx, mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	for _, tag := range device.Tags {
+		require.False(t, strings.HasPrefix(tag, hvtag.DeviceTagKeyPermanentError.Prefix()))
+		require.False(t, strings.HasPrefix(tag, hvtag.DeviceTagKeyTicketID.Prefix()))
+	}
+}
+
+func Test_ReconcileSupportTicket_missingTag(t *testing.T) {
+	ctx := context.Background()
+	hvClient := mockclient.NewMockedHVClientFactory().NewClient("dummy-key")
+	quarantineDevice(t, hvClient, mockclient.FreeDeviceID, "my-cluster", time.Now())
+
+	// a ticket was opened, but the ticket tag was not set
+	ticketID, err := hvClient.CreateTicket(ctx, supportTicketSubject(mockclient.FreeDeviceID), "body")
+	require.NoError(t, err)
+
+	state, gotTicketID, err := ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, SupportTicketPending, state)
+	require.Equal(t, ticketID, gotTicketID)
+
+	tickets, err := hvClient.ListTickets(ctx)
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+
+	device, err := hvClient.GetDevice(ctx, mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	id, err := ticketIDFromTags(device.Tags)
+	require.NoError(t, err)
+	require.Equal(t, ticketID, id)
+}
+
+func Test_ReconcileSupportTicket_unhealthy(t *testing.T) {
+	ctx := context.Background()
+	hvClient := mockclient.NewMockedHVClientFactory().NewClient("dummy-key")
+	quarantineDevice(t, hvClient, mockclient.FreeDeviceID, "my-cluster", time.Now())
+
+	state, ticketID, err := ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, SupportTicketOpened, state)
+
+	// the ticket was resolved, but the device is still broken
+	mockclient.SetTicketStatus(hvClient, ticketID, "Closed")
+	mockclient.SetIPMISensors(hvClient, mockclient.FreeDeviceID, []hvclient.IPMISensor{
+		{SensorID: "2", Name: "FAN1", Group: "Fan", Units: "RPM", Reading: 0, Status: ptr.To(false)},
+	})
+	state, gotTicketID, err := ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.ErrorIs(t, err, ErrDeviceUnhealthy)
+	require.Equal(t, SupportTicketUnhealthy, state)
+	require.Equal(t, ticketID, gotTicketID)
+
+	device, err := hvClient.GetDevice(ctx, mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	_, err = hvtag.PermanentErrorTagFromList(device.Tags)
+	require.NoError(t, err)
+	_, err = ticketIDFromTags(device.Tags)
+	require.ErrorIs(t, err, hvtag.ErrDeviceTagNotFound)
+
+	// a follow-up ticket gets opened
+	state, followUpID, err := ReconcileSupportTicket(ctx, hvClient, "my-cluster", mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	require.Equal(t, SupportTicketOpened, state)
+	require.NotEqual(t, ticketID, followUpID)
+}
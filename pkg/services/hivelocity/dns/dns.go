@@ -0,0 +1,211 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dns implements functions to manage DNS records of clusters and devices in Hivelocity.
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	hv "github.com/hivelocity/hivelocity-client-go/client"
+	"golang.org/x/exp/slices"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+// ErrPTRRecordNotFound indicates that no PTR record exists for an address.
+var ErrPTRRecordNotFound = fmt.Errorf("ptr record not found")
+
+// ReconcileAddressRecords makes sure that the A and AAAA records with the given name point to the given addresses.
+// IPv4 addresses are kept in one A record, every IPv6 address gets its own AAAA record.
+func ReconcileAddressRecords(ctx context.Context, hvClient hvclient.Client, zone, name string, ttl int32, addresses []string) error {
+	ipv4Addresses, ipv6Addresses := splitAddresses(addresses)
+	if err := reconcileARecord(ctx, hvClient, zone, name, ttl, ipv4Addresses); err != nil {
+		return err
+	}
+	return reconcileAAAARecords(ctx, hvClient, zone, name, ttl, ipv6Addresses)
+}
+
+// DeleteAddressRecords deletes the A and AAAA records with the given name. Nothing is done if they do not exist.
+func DeleteAddressRecords(ctx context.Context, hvClient hvclient.Client, zone, name string) error {
+	if err := hvClient.DeleteARecord(ctx, zone, name); err != nil {
+		return fmt.Errorf("failed to delete A record %q: %w", name, err)
+	}
+	return reconcileAAAARecords(ctx, hvClient, zone, name, 0, nil)
+}
+
+// reconcileARecord makes sure that the A record points to the given IPv4 addresses.
+// The record gets deleted if there are no addresses.
+func reconcileARecord(ctx context.Context, hvClient hvclient.Client, zone, name string, ttl int32, addresses []string) error {
+	if len(addresses) == 0 {
+		if err := hvClient.DeleteARecord(ctx, zone, name); err != nil {
+			return fmt.Errorf("failed to delete A record %q: %w", name, err)
+		}
+		return nil
+	}
+
+	records, err := hvClient.ListARecords(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("failed to list A records of zone %q: %w", zone, err)
+	}
+
+	desired := hv.ARecord{
+		Name:      name,
+		Ttl:       ttl,
+		Addresses: addresses,
+	}
+
+	for _, record := range records {
+		if record.Name != name {
+			continue
+		}
+		if record.Ttl == desired.Ttl && slices.Equal(sortedAddresses(record.Addresses), desired.Addresses) {
+			// nothing to do
+			return nil
+		}
+		if err := hvClient.UpdateARecord(ctx, zone, desired); err != nil {
+			return fmt.Errorf("failed to update A record %q: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := hvClient.CreateARecord(ctx, zone, desired); err != nil {
+		return fmt.Errorf("failed to create A record %q: %w", name, err)
+	}
+	return nil
+}
+
+// reconcileAAAARecords makes sure that there is exactly one AAAA record for each of the given IPv6 addresses.
+func reconcileAAAARecords(ctx context.Context, hvClient hvclient.Client, zone, name string, ttl int32, addresses []string) error {
+	records, err := hvClient.ListAAAARecords(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("failed to list AAAA records of zone %q: %w", zone, err)
+	}
+
+	existing := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		if record.Name != name {
+			continue
+		}
+		address := normalizeAddress(record.Address)
+		_, duplicate := existing[address]
+		if !duplicate && record.Ttl == ttl && slices.Contains(addresses, address) {
+			existing[address] = struct{}{}
+			continue
+		}
+		if err := hvClient.DeleteAAAARecord(ctx, zone, record.Id); err != nil {
+			return fmt.Errorf("failed to delete AAAA record %q (%s): %w", name, record.Address, err)
+		}
+	}
+
+	for _, address := range addresses {
+		if _, ok := existing[address]; ok {
+			continue
+		}
+		record := hv.AaaaRecordCreate{Name: name, Ttl: ttl, Address: address}
+		if err := hvClient.CreateAAAARecord(ctx, zone, record); err != nil {
+			return fmt.Errorf("failed to create AAAA record %q (%s): %w", name, address, err)
+		}
+	}
+	return nil
+}
+
+// ReconcilePTRRecord makes sure that the PTR record of the address points to the given name.
+// Hivelocity creates the PTR records of assigned addresses, so they only get updated.
+// ErrPTRRecordNotFound gets returned if the address has no PTR record.
+func ReconcilePTRRecord(ctx context.Context, hvClient hvclient.Client, address, name string, ttl int32) error {
+	records, err := hvClient.ListPTRRecords(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list PTR records: %w", err)
+	}
+
+	for _, record := range records {
+		if record.Address != address {
+			continue
+		}
+		if record.Name == name && record.Ttl == ttl {
+			// nothing to do
+			return nil
+		}
+		if err := hvClient.UpdatePTRRecord(ctx, record.Id, hv.PtrRecordUpdate{Name: name, Ttl: ttl}); err != nil {
+			return fmt.Errorf("failed to update PTR record %d of %q: %w", record.Id, address, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("address %q: %w", address, ErrPTRRecordNotFound)
+}
+
+// HealthyControlPlaneAddresses returns the external IPs of all control planes which are ready and not being deleted.
+// Both lists are expected to be ordered in the same way, like they get returned by ClusterScope.ListMachines().
+func HealthyControlPlaneAddresses(machines []*clusterv1.Machine, hvMachines []*infrav1.HivelocityMachine) []string {
+	var addresses []string
+	for i := range machines {
+		if i >= len(hvMachines) {
+			break
+		}
+		machine, hvMachine := machines[i], hvMachines[i]
+		if !util.IsControlPlaneMachine(machine) {
+			continue
+		}
+		if !hvMachine.DeletionTimestamp.IsZero() || !hvMachine.Status.Ready {
+			continue
+		}
+		for _, address := range hvMachine.Status.Addresses {
+			if address.Type == clusterv1.MachineExternalIP && address.Address != "" {
+				addresses = append(addresses, address.Address)
+			}
+		}
+	}
+	return sortedAddresses(addresses)
+}
+
+// splitAddresses returns the sorted IPv4 and IPv6 addresses. Invalid addresses are skipped.
+func splitAddresses(addresses []string) (ipv4Addresses, ipv6Addresses []string) {
+	for _, address := range sortedAddresses(addresses) {
+		ip := net.ParseIP(address)
+		switch {
+		case ip == nil:
+			continue
+		case ip.To4() != nil:
+			ipv4Addresses = append(ipv4Addresses, address)
+		default:
+			ipv6Addresses = append(ipv6Addresses, address)
+		}
+	}
+	return ipv4Addresses, ipv6Addresses
+}
+
+// sortedAddresses returns a sorted copy of the normalized addresses without duplicates.
+func sortedAddresses(addresses []string) []string {
+	sorted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		sorted = append(sorted, normalizeAddress(address))
+	}
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// normalizeAddress returns the canonical form of an IP address, so that equal IPv6 addresses can be compared as strings.
+func normalizeAddress(address string) string {
+	if ip := net.ParseIP(address); ip != nil {
+		return ip.String()
+	}
+	return address
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"testing"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func Test_ReconcileAddressRecords(t *testing.T) {
+	client := mock.NewMockedHVClientFactory().NewClient("dummy-key")
+	ctx := context.Background()
+
+	// create
+	err := ReconcileAddressRecords(ctx, client, mock.DNSZone, "api.example.com", 300, []string{"10.0.0.2", "10.0.0.1", "2001:db8::2"})
+	require.NoError(t, err)
+	records, err := client.ListARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, records[0].Addresses)
+	aaaaRecords, err := client.ListAAAARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Len(t, aaaaRecords, 1)
+	require.Equal(t, "2001:db8::2", aaaaRecords[0].Address)
+
+	// update
+	err = ReconcileAddressRecords(ctx, client, mock.DNSZone, "api.example.com", 300, []string{"10.0.0.3", "2001:0db8::2", "2001:db8::3"})
+	require.NoError(t, err)
+	records, err = client.ListARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, []string{"10.0.0.3"}, records[0].Addresses)
+	aaaaRecords, err = client.ListAAAARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Len(t, aaaaRecords, 2)
+
+	// IPv6 only
+	err = ReconcileAddressRecords(ctx, client, mock.DNSZone, "api.example.com", 300, []string{"2001:db8::3"})
+	require.NoError(t, err)
+	records, err = client.ListARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Empty(t, records)
+	aaaaRecords, err = client.ListAAAARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Len(t, aaaaRecords, 1)
+	require.Equal(t, "2001:db8::3", aaaaRecords[0].Address)
+
+	// unknown zone
+	err = ReconcileAddressRecords(ctx, client, "unknown.com", "api.unknown.com", 300, []string{"10.0.0.3"})
+	require.ErrorIs(t, err, hvclient.ErrDNSZoneNotFound)
+
+	// delete twice
+	require.NoError(t, DeleteAddressRecords(ctx, client, mock.DNSZone, "api.example.com"))
+	require.NoError(t, DeleteAddressRecords(ctx, client, mock.DNSZone, "api.example.com"))
+	records, err = client.ListARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Empty(t, records)
+	aaaaRecords, err = client.ListAAAARecords(ctx, mock.DNSZone)
+	require.NoError(t, err)
+	require.Empty(t, aaaaRecords)
+}
+
+func Test_ReconcilePTRRecord(t *testing.T) {
+	client := mock.NewMockedHVClientFactory().NewClient("dummy-key")
+	ctx := context.Background()
+
+	err := ReconcilePTRRecord(ctx, client, mock.DefaultPTRRecord.Address, "node.example.com", 300)
+	require.NoError(t, err)
+	records, err := client.ListPTRRecords(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "node.example.com", records[0].Name)
+	require.Equal(t, int32(300), records[0].Ttl)
+
+	err = ReconcilePTRRecord(ctx, client, "10.10.10.10", "node.example.com", 300)
+	require.ErrorIs(t, err, ErrPTRRecordNotFound)
+}
+
+func Test_HealthyControlPlaneAddresses(t *testing.T) {
+	newMachines := func(controlPlane, ready, deleting bool, ip string) (*clusterv1.Machine, *infrav1.HivelocityMachine) {
+		machine := &clusterv1.Machine{}
+		if controlPlane {
+			machine.Labels = map[string]string{clusterv1.MachineControlPlaneLabel: ""}
+		}
+		hvMachine := &infrav1.HivelocityMachine{}
+		hvMachine.Status.Ready = ready
+		if deleting {
+			now := metav1.Now()
+			hvMachine.DeletionTimestamp = &now
+		}
+		hvMachine.Status.Addresses = []clusterv1.MachineAddress{
+			{Type: clusterv1.MachineInternalIP, Address: ip},
+			{Type: clusterv1.MachineExternalIP, Address: ip},
+		}
+		return machine, hvMachine
+	}
+
+	var machines []*clusterv1.Machine
+	var hvMachines []*infrav1.HivelocityMachine
+	for _, tc := range []struct {
+		controlPlane, ready, deleting bool
+		ip                            string
+	}{
+		{true, true, false, "10.0.0.2"},
+		{true, true, false, "10.0.0.1"},
+		{true, false, false, "10.0.0.3"},
+		{true, true, true, "10.0.0.4"},
+		{false, true, false, "10.0.0.5"},
+	} {
+		machine, hvMachine := newMachines(tc.controlPlane, tc.ready, tc.deleting, tc.ip)
+		machines = append(machines, machine)
+		hvMachines = append(hvMachines, hvMachine)
+	}
+
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, HealthyControlPlaneAddresses(machines, hvMachines))
+	require.Empty(t, HealthyControlPlaneAddresses(nil, nil))
+}
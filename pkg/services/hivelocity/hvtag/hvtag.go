@@ -20,6 +20,7 @@ package hvtag
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 )
@@ -43,6 +44,13 @@ const (
 	// DeviceTagKeyCAPHVUseAllowed is the key to allow device use by CAPI cluster.
 	DeviceTagKeyCAPHVUseAllowed DeviceTagKey = "caphv-use"
 
+	// DeviceTagKeyTicketID is the key for the ID of the support ticket which was opened for a quarantined device.
+	DeviceTagKeyTicketID DeviceTagKey = "caphv-ticket-id"
+
+	// DeviceTagKeyQuarantinedBy is the key for the name of the HivelocityCluster which set the permanent error tag.
+	// Only this cluster lists the device as quarantined and may clear the permanent error.
+	DeviceTagKeyQuarantinedBy DeviceTagKey = "caphv-quarantined-by"
+
 	// Attention: If you add a new DeviceTagKey, then extend the method IsValid()!
 )
 
@@ -72,7 +80,9 @@ func (key DeviceTagKey) IsValid() bool {
 		key == DeviceTagKeyCluster ||
 		key == DeviceTagKeyMachineType ||
 		key == DeviceTagKeyPermanentError ||
-		key == DeviceTagKeyCAPHVUseAllowed
+		key == DeviceTagKeyCAPHVUseAllowed ||
+		key == DeviceTagKeyTicketID ||
+		key == DeviceTagKeyQuarantinedBy
 }
 
 // DeviceTag defines the object that represents a key-value pair that is stored as tag of Hivelocity devices.
@@ -135,6 +145,73 @@ func PermanentErrorTagFromList(tagList []string) (DeviceTag, error) {
 	return DeviceTagFromList(DeviceTagKeyPermanentError, tagList)
 }
 
+// PermanentErrorTag returns the permanent error tag for the given reason and time,
+// e.g. "caphv-permanent-error=remediation-failed-2023-01-02T15:04:05Z".
+func PermanentErrorTag(reason string, t time.Time) DeviceTag {
+	return DeviceTag{
+		Key:   DeviceTagKeyPermanentError,
+		Value: fmt.Sprintf("%s-%s", reason, t.UTC().Format(time.RFC3339)),
+	}
+}
+
+// QuarantinedByTagFromList returns the tag of the cluster which quarantined the device from a list of tag strings.
+func QuarantinedByTagFromList(tagList []string) (DeviceTag, error) {
+	return DeviceTagFromList(DeviceTagKeyQuarantinedBy, tagList)
+}
+
+// AddPermanentErrorTag adds the permanent error tag to the list of tag strings. If the list contains a cluster tag,
+// the cluster is stored in the quarantined-by tag, because the cluster tag gets removed once the device is released.
+// The list is not updated if it contains a permanent error tag already, so that the first error is kept.
+func AddPermanentErrorTag(tagList []string, deviceTag DeviceTag) (newTagList []string, updated bool) {
+	for _, tagString := range tagList {
+		if strings.HasPrefix(tagString, DeviceTagKeyPermanentError.Prefix()) {
+			return tagList, false
+		}
+	}
+	newTagList = make([]string, 0, len(tagList)+2)
+	newTagList = append(newTagList, tagList...)
+	newTagList = append(newTagList, deviceTag.ToString())
+	if clusterTag, err := ClusterTagFromList(tagList); err == nil {
+		newTagList = append(newTagList, DeviceTag{Key: DeviceTagKeyQuarantinedBy, Value: clusterTag.Value}.ToString())
+	}
+	return newTagList, true
+}
+
+// RemovePermanentErrorTag removes all permanent error tags and ticket tags from the list of tag strings.
+// The ticket tag and the quarantined-by tag belong to the permanent error, so they get removed as well.
+func RemovePermanentErrorTag(tagList []string) (newTagList []string, updated bool) {
+	newTagList = make([]string, 0, len(tagList))
+	for _, tagString := range tagList {
+		if strings.HasPrefix(tagString, DeviceTagKeyTicketID.Prefix()) ||
+			strings.HasPrefix(tagString, DeviceTagKeyQuarantinedBy.Prefix()) {
+			continue
+		}
+		if strings.HasPrefix(tagString, DeviceTagKeyPermanentError.Prefix()) {
+			updated = true
+			continue
+		}
+		newTagList = append(newTagList, tagString)
+	}
+	return newTagList, updated
+}
+
+// ParsePermanentError splits the value of a permanent error tag into the reason and the time of the error,
+// e.g. "remediation-failed-2023-01-02T15:04:05Z". If the value contains no time, the whole value is the reason
+// and since is zero.
+func ParsePermanentError(value string) (reason string, since time.Time) {
+	for i := strings.Index(value, "-"); i >= 0; {
+		if t, err := time.Parse(time.RFC3339, value[i+1:]); err == nil {
+			return value[:i], t
+		}
+		next := strings.Index(value[i+1:], "-")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return value, time.Time{}
+}
+
 // DeviceUsableByCAPI returns if cluster can use the device.
 func DeviceUsableByCAPI(tagList []string) bool {
 	deviceTag, err := DeviceTagFromList(DeviceTagKeyCAPHVUseAllowed, tagList)
@@ -206,6 +283,8 @@ func isEphemeralTag(tag string) bool {
 	for _, keepPrefix := range []string{
 		string(DeviceTagKeyPermanentError),
 		string(DeviceTagKeyCAPHVUseAllowed),
+		string(DeviceTagKeyTicketID),
+		string(DeviceTagKeyQuarantinedBy),
 	} {
 		if strings.HasPrefix(tag, keepPrefix+"=") {
 			return false
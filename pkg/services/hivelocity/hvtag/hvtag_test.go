@@ -33,6 +33,7 @@ package hvtag
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -86,6 +87,14 @@ var _ = Describe("Test DeviceTagKey.IsValid", func() {
 			key:           DeviceTagKeyMachineType,
 			expectIsValid: true,
 		}),
+		Entry("ticket id key", testCaseDeviceTagKeyIsValid{
+			key:           DeviceTagKeyTicketID,
+			expectIsValid: true,
+		}),
+		Entry("quarantined by key", testCaseDeviceTagKeyIsValid{
+			key:           DeviceTagKeyQuarantinedBy,
+			expectIsValid: true,
+		}),
 		Entry("other key", testCaseDeviceTagKeyIsValid{
 			key:           "caphv-other",
 			expectIsValid: false,
@@ -364,3 +373,87 @@ var _ = Describe("PermanentErrorTagFromList", func() {
 		}))
 	})
 })
+
+var _ = Describe("Test AddPermanentErrorTag", func() {
+	tag := PermanentErrorTag("remediation-failed", time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC))
+
+	It("formats the tag", func() {
+		Expect(tag.ToString()).Should(Equal("caphv-permanent-error=remediation-failed-2023-01-02T15:04:05Z"))
+	})
+
+	It("adds the tag", func() {
+		tags, updated := AddPermanentErrorTag([]string{"caphv-use=allow"}, tag)
+		Expect(updated).Should(BeTrue())
+		Expect(tags).Should(Equal([]string{"caphv-use=allow", tag.ToString()}))
+
+		_, err := PermanentErrorTagFromList(tags)
+		Expect(err).Should(Succeed())
+	})
+
+	It("stores the cluster in the quarantined-by tag", func() {
+		tags, updated := AddPermanentErrorTag([]string{"caphv-use=allow", "caphv-cluster-name=my-cluster"}, tag)
+		Expect(updated).Should(BeTrue())
+		Expect(tags).Should(ContainElement("caphv-quarantined-by=my-cluster"))
+
+		// the quarantined-by tag is kept when the device is released
+		quarantinedBy, err := QuarantinedByTagFromList(RemoveEphemeralTags(tags))
+		Expect(err).Should(Succeed())
+		Expect(quarantinedBy.Value).Should(Equal("my-cluster"))
+	})
+
+	It("keeps an existing permanent error", func() {
+		existing := []string{"caphv-use=allow", "caphv-permanent-error=reloading-since-2022-01-02T15:04:05Z"}
+		tags, updated := AddPermanentErrorTag(existing, tag)
+		Expect(updated).Should(BeFalse())
+		Expect(tags).Should(Equal(existing))
+	})
+})
+
+var _ = Describe("Test RemovePermanentErrorTag", func() {
+	It("removes the tag, the ticket tag and the quarantined-by tag", func() {
+		tags, updated := RemovePermanentErrorTag([]string{
+			"caphv-use=allow",
+			"caphv-permanent-error=remediation-failed-2023-01-02T15:04:05Z",
+			"caphv-ticket-id=42",
+			"caphv-quarantined-by=my-cluster",
+		})
+		Expect(updated).Should(BeTrue())
+		Expect(tags).Should(Equal([]string{"caphv-use=allow"}))
+	})
+
+	It("does not update a list without the tag", func() {
+		tags, updated := RemovePermanentErrorTag([]string{"caphv-use=allow"})
+		Expect(updated).Should(BeFalse())
+		Expect(tags).Should(Equal([]string{"caphv-use=allow"}))
+	})
+})
+
+var _ = Describe("Test ParsePermanentError", func() {
+	type testCaseParsePermanentError struct {
+		value        string
+		expectReason string
+		expectSince  time.Time
+	}
+
+	DescribeTable("Test ParsePermanentError",
+		func(tc testCaseParsePermanentError) {
+			reason, since := ParsePermanentError(tc.value)
+			Expect(reason).Should(Equal(tc.expectReason))
+			Expect(since.Equal(tc.expectSince)).Should(BeTrue())
+		},
+		Entry("remediation failed", testCaseParsePermanentError{
+			value:        "remediation-failed-2023-01-02T15:04:05Z",
+			expectReason: "remediation-failed",
+			expectSince:  time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		}),
+		Entry("reloading with time zone", testCaseParsePermanentError{
+			value:        "reloading-since-2023-01-02T17:04:05+02:00",
+			expectReason: "reloading-since",
+			expectSince:  time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		}),
+		Entry("no time", testCaseParsePermanentError{
+			value:        "my-permanent-error",
+			expectReason: "my-permanent-error",
+		}),
+	)
+})
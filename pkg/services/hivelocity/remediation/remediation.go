@@ -0,0 +1,245 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remediation implements functions to remediate unhealthy Hivelocity machines.
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/metrics"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// defaultTimeout is used if the strategy of the remediation has no timeout.
+const defaultTimeout = 5 * time.Minute
+
+// permanentErrorReason is the reason in the permanent error tag of devices which could not be remediated.
+const permanentErrorReason = "remediation-failed"
+
+// Service defines struct with remediation scope to remediate Hivelocity machines.
+type Service struct {
+	scope *scope.RemediationScope
+}
+
+// NewService outs a new service with remediation scope.
+func NewService(scope *scope.RemediationScope) *Service {
+	return &Service{
+		scope: scope,
+	}
+}
+
+// Reconcile remediates the unhealthy machine. Depending on the strategy, the device gets rebooted or reprovisioned up
+// to RetryLimit times, waiting Timeout after each attempt. If the machine is still unhealthy after that, the CAPI
+// Machine gets deleted.
+// The HivelocityRemediation gets deleted by the MachineHealthCheck as soon as the machine is healthy again.
+func (s *Service) Reconcile(ctx context.Context) (reconcile.Result, error) {
+	remediation := s.scope.HivelocityRemediation
+	strategy := remediation.Spec.Strategy
+
+	if strategy == nil {
+		s.scope.Info("HivelocityRemediation has no strategy, nothing to do")
+		return reconcile.Result{}, nil
+	}
+
+	if strategy.Type != infrav1.RemediationTypeReboot && strategy.Type != infrav1.RemediationTypeReprovision {
+		s.scope.Info("unsupported remediation type", "type", strategy.Type)
+		return reconcile.Result{}, nil
+	}
+
+	switch remediation.Status.Phase {
+	case "", infrav1.PhaseRunning, infrav1.PhaseWaiting:
+		return s.reconcileRetries(ctx)
+	case infrav1.PhaseDeleting:
+		return reconcile.Result{}, s.deleteMachine(ctx)
+	default:
+		return reconcile.Result{}, nil
+	}
+}
+
+// reconcileRetries remediates the device whenever the timeout of the last attempt is over, until no retries are left.
+func (s *Service) reconcileRetries(ctx context.Context) (reconcile.Result, error) {
+	remediation := s.scope.HivelocityRemediation
+	timeout := remediationTimeout(remediation.Spec.Strategy)
+
+	if remediation.Status.Phase == "" {
+		remediation.Status.Phase = infrav1.PhaseRunning
+	}
+
+	if remediation.Status.Phase == infrav1.PhaseWaiting {
+		if wait := timeUntilTimeout(remediation.Status.LastRemediated, timeout); wait > 0 {
+			// the machine has not become healthy yet, but the device needs more time.
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	if remediation.Status.RetryCount >= remediation.Spec.Strategy.RetryLimit {
+		s.setPhaseDeleting("retry limit of %d reached", remediation.Spec.Strategy.RetryLimit)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	deviceID, err := s.scope.HivelocityMachine.DeviceIDFromProviderID()
+	if err != nil {
+		s.setPhaseDeleting("machine has no device: %s", err)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	var remediate func(context.Context, int32) error
+	switch remediation.Spec.Strategy.Type {
+	case infrav1.RemediationTypeReprovision:
+		if state := s.scope.HivelocityMachine.Spec.Status.ProvisioningState; state != infrav1.StateDeviceProvisioned {
+			// the device is still being provisioned, e.g. by the last attempt.
+			s.scope.Info("waiting for device to be provisioned", "provisioningState", state)
+			return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		remediate = s.reprovisionDevice
+	default:
+		remediate = s.rebootDevice
+	}
+
+	if err := remediate(ctx, deviceID); err != nil {
+		if errors.Is(err, hvclient.ErrDeviceNotFound) {
+			s.setPhaseDeleting("device %d not found", deviceID)
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	now := metav1.Now()
+	remediation.Status.RetryCount++
+	remediation.Status.LastRemediated = &now
+	remediation.Status.Phase = infrav1.PhaseWaiting
+
+	return reconcile.Result{RequeueAfter: timeout}, nil
+}
+
+// rebootDevice power-cycles the device. Unlike a soft reboot, this also works if the operating system hangs.
+func (s *Service) rebootDevice(ctx context.Context, deviceID int32) error {
+	if err := s.scope.HVClient.PowerCycleDevice(ctx, deviceID); err != nil {
+		return fmt.Errorf("failed to power-cycle device %d: %w", deviceID, err)
+	}
+	remediation := s.scope.HivelocityRemediation
+	record.Eventf(remediation, "RebootDevice", "Rebooted device %d of unhealthy machine (retry %d of %d)",
+		deviceID, remediation.Status.RetryCount+1, remediation.Spec.Strategy.RetryLimit)
+	return nil
+}
+
+// reprovisionDevice moves the HivelocityMachine back to StateVerifyShutdown. The HivelocityMachine controller then
+// shuts the device down and provisions it again with the current bootstrap data. The device and its IPs are kept.
+func (s *Service) reprovisionDevice(ctx context.Context, deviceID int32) error {
+	if _, err := s.scope.HVClient.GetDevice(ctx, deviceID); err != nil {
+		return fmt.Errorf("failed to get device %d: %w", deviceID, err)
+	}
+
+	hvMachine := s.scope.HivelocityMachine
+	hvMachine.Spec.Status.ProvisioningState = infrav1.StateVerifyShutdown
+	hvMachine.Status.Ready = false
+	conditions.MarkFalse(hvMachine, infrav1.DeviceReadyCondition, infrav1.DeviceReprovisioningReason,
+		clusterv1.ConditionSeverityWarning, "device gets reprovisioned by remediation")
+
+	remediation := s.scope.HivelocityRemediation
+	record.Eventf(hvMachine, "ReprovisionDevice", "Reprovisioning device %d of unhealthy machine", deviceID)
+	record.Eventf(remediation, "ReprovisionDevice", "Reprovisioning device %d of unhealthy machine (retry %d of %d)",
+		deviceID, remediation.Status.RetryCount+1, remediation.Spec.Strategy.RetryLimit)
+	return nil
+}
+
+// setPhaseDeleting gives up on remediating the device, so that the machine gets deleted.
+func (s *Service) setPhaseDeleting(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	s.scope.Info("remediation failed, deleting machine", "reason", msg)
+	record.Warnf(s.scope.HivelocityRemediation, "RemediationFailed", "Deleting unhealthy machine: %s", msg)
+	s.scope.HivelocityRemediation.Status.Phase = infrav1.PhaseDeleting
+}
+
+// deleteMachine quarantines the device and deletes the CAPI Machine, so that its owner can replace it with a machine
+// on another device.
+func (s *Service) deleteMachine(ctx context.Context) error {
+	if !s.scope.Machine.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	if err := s.quarantineDevice(ctx); err != nil {
+		return err
+	}
+	if err := s.scope.Client.Delete(ctx, s.scope.Machine); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete machine %s/%s: %w", s.scope.Machine.Namespace, s.scope.Machine.Name, err)
+	}
+	record.Eventf(s.scope.HivelocityRemediation, "MachineDeleted", "Deleted unhealthy machine %s", s.scope.Machine.Name)
+	return nil
+}
+
+// quarantineDevice sets the permanent error tag on the device of the machine, so that it does not go back to the pool
+// of free devices. Nothing is done if the machine has no device.
+func (s *Service) quarantineDevice(ctx context.Context) error {
+	deviceID, err := s.scope.HivelocityMachine.DeviceIDFromProviderID()
+	if err != nil {
+		return nil
+	}
+
+	device, err := s.scope.HVClient.GetDevice(ctx, deviceID)
+	if err != nil {
+		if errors.Is(err, hvclient.ErrDeviceNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get device %d: %w", deviceID, err)
+	}
+
+	tag := hvtag.PermanentErrorTag(permanentErrorReason, time.Now())
+	tags, updated := hvtag.AddPermanentErrorTag(device.Tags, tag)
+	if !updated {
+		return nil
+	}
+	if err := s.scope.HVClient.SetDeviceTags(ctx, deviceID, tags); err != nil {
+		return fmt.Errorf("failed to set permanent error tag on device %d: %w", deviceID, err)
+	}
+
+	metrics.RecordDeviceQuarantined(permanentErrorReason)
+	record.Warnf(s.scope.HivelocityRemediation, "DeviceQuarantined",
+		"Remediation of device %d failed. Tag %q was set, the device will not be used again", deviceID, tag.ToString())
+	return nil
+}
+
+// remediationTimeout returns the time to wait after each remediation step.
+func remediationTimeout(strategy *infrav1.RemediationStrategy) time.Duration {
+	if strategy.Timeout == nil || strategy.Timeout.Duration <= 0 {
+		return defaultTimeout
+	}
+	return strategy.Timeout.Duration
+}
+
+// timeUntilTimeout returns how long to wait until the timeout is over. It returns zero if the timeout is over.
+func timeUntilTimeout(lastRemediated *metav1.Time, timeout time.Duration) time.Duration {
+	if lastRemediated == nil {
+		return 0
+	}
+	wait := time.Until(lastRemediated.Add(timeout))
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
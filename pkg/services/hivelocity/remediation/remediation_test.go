@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/scope"
+	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/hvtag"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestService(t *testing.T, providerID string, strategy *infrav1.RemediationStrategy) *Service {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clusterv1.AddToScheme(scheme))
+	require.NoError(t, infrav1.AddToScheme(scheme))
+
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"}}
+	hvMachine := &infrav1.HivelocityMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "hv-machine", Namespace: "default"},
+		Spec:       infrav1.HivelocityMachineSpec{ProviderID: &providerID},
+	}
+	hvRemediation := &infrav1.HivelocityRemediation{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+		Spec:       infrav1.HivelocityRemediationSpec{Strategy: strategy},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine, hvRemediation).Build()
+	remediationScope, err := scope.NewRemediationScope(scope.RemediationScopeParams{
+		Client:                c,
+		Logger:                logr.Discard(),
+		HVClient:              mockclient.NewMockedHVClientFactory().NewClient(""),
+		Machine:               machine,
+		HivelocityMachine:     hvMachine,
+		HivelocityRemediation: hvRemediation,
+	})
+	require.NoError(t, err)
+	return NewService(remediationScope)
+}
+
+func Test_Reconcile_reboot(t *testing.T) {
+	ctx := context.Background()
+	strategy := &infrav1.RemediationStrategy{
+		Type:       infrav1.RemediationTypeReboot,
+		RetryLimit: 2,
+		Timeout:    &metav1.Duration{Duration: time.Minute},
+	}
+	s := newTestService(t, fmt.Sprintf("hivelocity://%d", mockclient.FreeDeviceID), strategy)
+	status := &s.scope.HivelocityRemediation.Status
+
+	// first reboot
+	res, err := s.Reconcile(ctx)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, res.RequeueAfter)
+	require.Equal(t, infrav1.PhaseWaiting, status.Phase)
+	require.Equal(t, 1, status.RetryCount)
+	require.NotNil(t, status.LastRemediated)
+
+	// timeout not over yet
+	res, err = s.Reconcile(ctx)
+	require.NoError(t, err)
+	require.Greater(t, res.RequeueAfter, time.Duration(0))
+	require.Equal(t, 1, status.RetryCount)
+
+	// second reboot after timeout
+	status.LastRemediated = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	_, err = s.Reconcile(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, status.RetryCount)
+	require.Equal(t, infrav1.PhaseWaiting, status.Phase)
+
+	// retry limit reached after timeout
+	status.LastRemediated = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	_, err = s.Reconcile(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, status.RetryCount)
+	require.Equal(t, infrav1.PhaseDeleting, status.Phase)
+
+	// device gets quarantined and machine gets deleted
+	_, err = s.Reconcile(ctx)
+	require.NoError(t, err)
+	err = s.scope.Client.Get(ctx, client.ObjectKeyFromObject(s.scope.Machine), &clusterv1.Machine{})
+	require.True(t, apierrors.IsNotFound(err))
+
+	device, err := s.scope.HVClient.GetDevice(ctx, mockclient.FreeDeviceID)
+	require.NoError(t, err)
+	tag, err := hvtag.PermanentErrorTagFromList(device.Tags)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(tag.Value, "remediation-failed-"))
+}
+
+func Test_Reconcile_deviceNotFound(t *testing.T) {
+	strategy := &infrav1.RemediationStrategy{
+		Type:       infrav1.RemediationTypeReboot,
+		RetryLimit: 1,
+		Timeout:    &metav1.Duration{Duration: time.Minute},
+	}
+	s := newTestService(t, "hivelocity://424242", strategy)
+
+	_, err := s.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, infrav1.PhaseDeleting, s.scope.HivelocityRemediation.Status.Phase)
+	require.Equal(t, 0, s.scope.HivelocityRemediation.Status.RetryCount)
+}
+
+func Test_timeUntilTimeout(t *testing.T) {
+	require.Equal(t, time.Duration(0), timeUntilTimeout(nil, time.Minute))
+	require.Equal(t, time.Duration(0), timeUntilTimeout(&metav1.Time{Time: time.Now().Add(-2 * time.Minute)}, time.Minute))
+	wait := timeUntilTimeout(&metav1.Time{Time: time.Now()}, time.Minute)
+	require.Greater(t, wait, 50*time.Second)
+	require.LessOrEqual(t, wait, time.Minute)
+}
+
+func Test_Reconcile_reprovision(t *testing.T) {
+	ctx := context.Background()
+	strategy := &infrav1.RemediationStrategy{
+		Type:       infrav1.RemediationTypeReprovision,
+		RetryLimit: 1,
+		Timeout:    &metav1.Duration{Duration: time.Minute},
+	}
+	s := newTestService(t, fmt.Sprintf("hivelocity://%d", mockclient.FreeDeviceID), strategy)
+	hvMachine := s.scope.HivelocityMachine
+	hvMachine.Spec.Status.ProvisioningState = infrav1.StateDeviceProvisioned
+	hvMachine.Status.Ready = true
+
+	res, err := s.Reconcile(ctx)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, res.RequeueAfter)
+	require.Equal(t, infrav1.StateVerifyShutdown, hvMachine.Spec.Status.ProvisioningState)
+	require.False(t, hvMachine.Status.Ready)
+	require.Equal(t, infrav1.PhaseWaiting, s.scope.HivelocityRemediation.Status.Phase)
+	require.Equal(t, 1, s.scope.HivelocityRemediation.Status.RetryCount)
+
+	// device is not provisioned yet after timeout
+	s.scope.HivelocityRemediation.Status.LastRemediated = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	s.scope.HivelocityRemediation.Spec.Strategy.RetryLimit = 2
+	_, err = s.Reconcile(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, s.scope.HivelocityRemediation.Status.RetryCount)
+	require.Equal(t, infrav1.StateVerifyShutdown, hvMachine.Spec.Status.ProvisioningState)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing implements OpenTelemetry tracing of reconciles and calls to the Hivelocity API.
+// Spans are only exported if Setup was called with an endpoint. Otherwise, the global no-op tracer provider is used.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
+	caphvversion "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the name of the tracer of CAPHV.
+	tracerName = "github.com/hivelocity/cluster-api-provider-hivelocity"
+
+	// serviceName is the name of the service in the exported spans.
+	serviceName = "caphv"
+)
+
+const (
+	// DeviceIDKey is the attribute key of the ID of the Hivelocity device.
+	DeviceIDKey = attribute.Key("hivelocity.device.id")
+
+	// ProvisioningStateKey is the attribute key of the provisioning state of a HivelocityMachine.
+	ProvisioningStateKey = attribute.Key("caphv.provisioning_state")
+)
+
+// Options configures the export of spans.
+type Options struct {
+	// Endpoint is the host and port of the OTLP gRPC receiver. Tracing is disabled if it is empty.
+	Endpoint string
+
+	// Insecure disables TLS for the connection to the endpoint.
+	Insecure bool
+
+	// SampleRatio is the ratio of traces which are sampled, between 0 and 1.
+	SampleRatio float64
+}
+
+// Setup configures the global tracer provider to export spans via OTLP.
+// The returned function flushes and stops the export. It has to be called before the process exits.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(caphvversion.Get().String()),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of CAPHV from the global tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// DeviceID returns the attribute of the ID of a Hivelocity device.
+func DeviceID(deviceID int32) attribute.KeyValue {
+	return DeviceIDKey.Int(int(deviceID))
+}
+
+// ProvisioningState returns the attribute of the provisioning state of a HivelocityMachine.
+func ProvisioningState(state infrav1.ProvisioningState) attribute.KeyValue {
+	return ProvisioningStateKey.String(string(state))
+}
+
+// End records the error, if any, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
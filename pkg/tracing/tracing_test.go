@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_disabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer(tracerName)
+
+	_, span := tracer.Start(context.Background(), "ok")
+	End(span, nil)
+	_, span = tracer.Start(context.Background(), "failed")
+	End(span, errors.New("failed"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Equal(t, "failed", spans[1].Status.Description)
+}
@@ -28,6 +28,7 @@ import (
 
 	infrav1 "github.com/hivelocity/cluster-api-provider-hivelocity/api/v1alpha1"
 	hvclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client"
+	"github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/fakeserver"
 	mockclient "github.com/hivelocity/cluster-api-provider-hivelocity/pkg/services/hivelocity/client/mock"
 	g "github.com/onsi/ginkgo/v2"
 	corev1 "k8s.io/api/core/v1"
@@ -98,6 +99,10 @@ func init() {
 	}
 }
 
+// FakeServerAPIKey is the API key for which HVClientFactory returns real clients of FakeServer.
+// Other keys get mocked clients.
+const FakeServerAPIKey = "fake-server-api-key"
+
 type (
 	// TestEnvironment encapsulates a Kubernetes local test environment.
 	TestEnvironment struct {
@@ -105,10 +110,34 @@ type (
 		client.Client
 		Config          *rest.Config
 		HVClientFactory hvclient.Factory
+		FakeServer      *fakeserver.Server
 		cancel          context.CancelFunc
 	}
+
+	// hvClientFactory returns real clients of the fake server for FakeServerAPIKey and mocked clients otherwise.
+	hvClientFactory struct {
+		mocked     hvclient.Factory
+		fakeServer hvclient.Factory
+	}
 )
 
+// NewClient implements hvclient.Factory.
+func (f *hvClientFactory) NewClient(hvAPIKey string) hvclient.Client {
+	if hvAPIKey == FakeServerAPIKey {
+		return f.fakeServer.NewClient(hvAPIKey)
+	}
+	return f.mocked.NewClient(hvAPIKey)
+}
+
+// Forget implements hvclient.Factory.
+func (f *hvClientFactory) Forget(hvAPIKey string) {
+	if hvAPIKey == FakeServerAPIKey {
+		f.fakeServer.Forget(hvAPIKey)
+		return
+	}
+	f.mocked.Forget(hvAPIKey)
+}
+
 // NewTestEnvironment creates a new environment spinning up a local api-server.
 func NewTestEnvironment() *TestEnvironment {
 	// initialize webhook here to be able to test the envtest install via webhookOptions
@@ -145,11 +174,18 @@ func NewTestEnvironment() *TestEnvironment {
 		klog.Fatalf("failed to set up webhook with manager for HivelocityMachine: %s", err)
 	}
 
+	fakeServer := fakeserver.NewServer()
+	fakeServer.SetAPIKey(FakeServerAPIKey)
+
 	return &TestEnvironment{
-		Manager:         mgr,
-		Client:          mgr.GetClient(),
-		Config:          mgr.GetConfig(),
-		HVClientFactory: mockclient.NewMockedHVClientFactory(),
+		Manager: mgr,
+		Client:  mgr.GetClient(),
+		Config:  mgr.GetConfig(),
+		HVClientFactory: &hvClientFactory{
+			mocked:     mockclient.NewMockedHVClientFactory(),
+			fakeServer: &hvclient.HivelocityFactory{BaseURL: fakeServer.URL()},
+		},
+		FakeServer: fakeServer,
 	}
 }
 
@@ -163,6 +199,7 @@ func (t *TestEnvironment) StartManager(ctx context.Context) error {
 // Stop stops the manager and cancels the context.
 func (t *TestEnvironment) Stop() error {
 	t.cancel()
+	t.FakeServer.Close()
 	return env.Stop()
 }
 